@@ -0,0 +1,54 @@
+package hotenv
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Binding reports the state of a BindAtomic registration.
+type Binding struct {
+	mu  sync.Mutex
+	err error
+}
+
+// Err returns the error from the most recent build, or nil if it succeeded.
+func (b *Binding) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *Binding) setErr(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// BindAtomic keeps ptr in sync with the default config. build is called with
+// a copy of the current keys right away and again after every reload; its
+// result is stored into ptr. If build fails, ptr keeps its previous value and
+// the error is logged and recorded on the returned Binding.
+func BindAtomic[T any](ptr *atomic.Pointer[T], build func(map[string]string) (*T, error)) *Binding {
+	ensureStarted("")
+	return BindAtomicTo(std(), ptr, build)
+}
+
+// BindAtomicTo is BindAtomic for a specific *Env.
+func BindAtomicTo[T any](e *Env, ptr *atomic.Pointer[T], build func(map[string]string) (*T, error)) *Binding {
+	b := &Binding{}
+	apply := func(c config) {
+		v, err := build(c.clone())
+		b.setErr(err)
+		if err != nil {
+			e.logError("bind build failed, keeping previous value", errAttr(err))
+			return
+		}
+		ptr.Store(v)
+	}
+
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	apply(e.snapshot())
+	e.addListener(func(_, cur config) { apply(cur) })
+	return b
+}
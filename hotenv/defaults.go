@@ -0,0 +1,36 @@
+package hotenv
+
+// SetDefault registers value as the default config's fallback for key, so
+// every reader of the key agrees on one default instead of repeating it at
+// each call site. It may be called before Init and at any time after; see
+// Getenv for where registered defaults rank.
+func SetDefault(key, value string) {
+	std().SetDefault(key, value)
+}
+
+// SetDefault is the *Env counterpart of the package-level SetDefault.
+func (e *Env) SetDefault(key, value string) {
+	e.defaults.Store(key, value)
+}
+
+// --------- Internals ----------
+
+// registeredDefault returns the default registered for key, if any.
+func (e *Env) registeredDefault(key string) (string, bool) {
+	v, ok := e.defaults.Load(key)
+	if !ok && e.opts.caseInsensitive {
+		v, ok = e.defaults.Load(e.foldKey(key))
+	}
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// orDefault returns v, or the default registered for key if v is empty.
+func (e *Env) orDefault(key, v string) string {
+	if v == "" {
+		v, _ = e.registeredDefault(key)
+	}
+	return v
+}
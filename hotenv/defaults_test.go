@@ -0,0 +1,32 @@
+package hotenv
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestRegisteredDefaults(t *testing.T) {
+	var seen int
+	e := startEnv(t, writeFile(t, ".env", "A=1\n"), OptDefaults(map[string]string{"PORT": "8080", "HOST": "localhost"}),
+		OptTypedValidator(func(v *View) error {
+			seen = v.GetInt("PORT")
+			return nil
+		}))
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := e.GetIntE("PORT"); err != nil || n != 8080 {
+		t.Errorf("GetIntE = %d, %v, want the registered default", n, err)
+	}
+	if _, err := e.GetIntE("MISSING"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetIntE of a missing key: %v, want ErrKeyNotFound", err)
+	}
+	if got := e.GetenvFlag(flag.NewFlagSet("test", flag.ContinueOnError), "host", "HOST", ""); got != "localhost" {
+		t.Errorf("GetenvFlag = %q, want the registered default", got)
+	}
+	if seen != 8080 {
+		t.Errorf("typed validator saw PORT = %d, want the registered default", seen)
+	}
+}
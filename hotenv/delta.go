@@ -0,0 +1,164 @@
+package hotenv
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// ReloadDelta describes how a reload changed the config.
+type ReloadDelta struct {
+	// Added holds keys that are new, with their values.
+	Added map[string]string
+	// Removed holds keys that disappeared, with their last values.
+	Removed map[string]string
+	// Changed holds keys whose value changed, as [old, new].
+	Changed map[string][2]string
+
+	sensitive func(key string) bool // nil means by name only, see Redacted
+}
+
+// Empty reports whether the delta contains no changes.
+func (d ReloadDelta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Redacted returns a copy of the delta with the values of sensitive keys
+// replaced by "***", suitable for logging. Key names are kept.
+func (d ReloadDelta) Redacted() ReloadDelta {
+	sensitive := d.sensitive
+	if sensitive == nil {
+		sensitive = isSecretName
+	}
+	mask := func(k, v string) string {
+		if sensitive(k) {
+			return redacted
+		}
+		return v
+	}
+	out := ReloadDelta{
+		Added:     make(map[string]string, len(d.Added)),
+		Removed:   make(map[string]string, len(d.Removed)),
+		Changed:   make(map[string][2]string, len(d.Changed)),
+		sensitive: d.sensitive,
+	}
+	for k, v := range d.Added {
+		out.Added[k] = mask(k, v)
+	}
+	for k, v := range d.Removed {
+		out.Removed[k] = mask(k, v)
+	}
+	for k, v := range d.Changed {
+		out.Changed[k] = [2]string{mask(k, v[0]), mask(k, v[1])}
+	}
+	return out
+}
+
+// OnReloadDelta registers fn to be called with the changes made by each
+// successful reload of the default config. It may be called before Init.
+// The returned func unregisters fn.
+func OnReloadDelta(fn func(delta ReloadDelta)) (cancel func()) {
+	return std().OnReloadDelta(fn)
+}
+
+// OnReloadDelta is the *Env counterpart of the package-level OnReloadDelta.
+// Hooks run in registration order on the reload goroutine, only after a
+// successful reload; a panicking hook is recovered and logged.
+func (e *Env) OnReloadDelta(fn func(delta ReloadDelta)) (cancel func()) {
+	return e.addListener(func(old, cur config) {
+		d := diff(old, cur)
+		d.sensitive = e.isSensitive
+		fn(d)
+	})
+}
+
+// diff computes the delta between two configs.
+func diff(old, cur config) ReloadDelta {
+	d := ReloadDelta{
+		Added:   map[string]string{},
+		Removed: map[string]string{},
+		Changed: map[string][2]string{},
+	}
+	for k, ov := range old.m {
+		nv, ok := cur.m[k]
+		switch {
+		case !ok:
+			d.Removed[k] = ov
+		case nv != ov:
+			d.Changed[k] = [2]string{ov, nv}
+		}
+	}
+	for k, nv := range cur.m {
+		if _, ok := old.m[k]; !ok {
+			d.Added[k] = nv
+		}
+	}
+	return d
+}
+
+// DiffAgainstProcessEnv reports keys present both in the default config's
+// file and in the process environment whose values differ, as
+// [file value, env value]. Values of sensitive-looking keys (PASSWORD,
+// SECRET, TOKEN, ...) are masked. Use it to confirm the file is a faithful
+// replacement for existing env vars before disabling the fallback.
+func DiffAgainstProcessEnv() map[string][2]string {
+	ensureStarted("")
+	return std().DiffAgainstProcessEnv()
+}
+
+// DiffAgainstProcessEnv is the *Env counterpart of the package-level DiffAgainstProcessEnv.
+func (e *Env) DiffAgainstProcessEnv() map[string][2]string {
+	out := map[string][2]string{}
+	for k, fv := range e.snapshot().m {
+		if ev, ok := os.LookupEnv(k); ok && ev != fv {
+			out[k] = [2]string{e.mask(k, fv), e.mask(k, ev)}
+		}
+	}
+	return out
+}
+
+// ReloadEvent summarizes a reload by key name only, so it is safe to log or
+// expose on an admin endpoint.
+type ReloadEvent struct {
+	// Time is when the reload happened (or, for a preview, was computed).
+	Time time.Time
+	// Keys is the number of keys after the reload.
+	Keys int
+	// Added, Removed and Changed list the affected key names, sorted.
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func newReloadEvent(old, cur config) ReloadEvent {
+	d := diff(old, cur)
+	return ReloadEvent{
+		Time:    time.Now(),
+		Keys:    len(cur.m),
+		Added:   slices.Sorted(maps.Keys(d.Added)),
+		Removed: slices.Sorted(maps.Keys(d.Removed)),
+		Changed: slices.Sorted(maps.Keys(d.Changed)),
+	}
+}
+
+// PreviewReload reads and validates the file(s) behind the default config as
+// they are now and reports what a reload would change, without applying it.
+func PreviewReload() (ReloadEvent, error) {
+	ensureStarted("")
+	return std().PreviewReload()
+}
+
+// PreviewReload is the *Env counterpart of the package-level PreviewReload.
+func (e *Env) PreviewReload() (ReloadEvent, error) {
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	if len(e.paths) == 0 {
+		return ReloadEvent{}, errNotStarted
+	}
+	_, _, c, err := e.readAll()
+	if err != nil {
+		return ReloadEvent{}, err
+	}
+	return newReloadEvent(e.snapshot(), c), nil
+}
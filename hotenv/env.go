@@ -0,0 +1,1083 @@
+package hotenv
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// errNotStarted is returned by operations that need a running Env.
+var errNotStarted = errors.New("hotenv: not started")
+
+// Env is a self-contained, hot-reloading view of one or more .env files.
+// Each Env owns its config, options and background watcher, so several
+// instances can run side by side without affecting each other.
+type Env struct {
+	cfg                  atomic.Value // holds config
+	opts                 options
+	fallbackToProcessEnv atomic.Bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+	paths     []string
+	prefixes  map[string]string // path -> key namespace, see InitNamespaced
+
+	// guarded by reloadMu
+	environment string   // last value reported by the environment resolver
+	layers      []config // last good parse of each source, see resolveSources
+
+	warnedDefaults sync.Map // key -> struct{}; keys already warned about by OptWarnOnDefault
+	defaults       sync.Map // key -> string; see SetDefault
+
+	reloads        atomic.Int64
+	reloadFailures atomic.Int64
+	watchErrors    atomic.Int64
+	lastReload     atomic.Int64          // unix nanos of the last successful load, 0 if none
+	lastError      atomic.Value          // string; error of the last load, "" if it succeeded
+	lastFailure    atomic.Int64          // unix nanos of the last failed reload, 0 if none
+	reloadErr      atomic.Pointer[error] // error of the last failed reload, nil if none
+	generation     atomic.Uint64
+	history        history
+
+	reloadMu  sync.Mutex // serializes loads and stores so listeners see reloads in order
+	mu        sync.Mutex // guards listeners and watchers
+	writeMu   sync.Mutex // serializes Setenv
+	listeners []*listener
+	watchers  map[string][]chan string
+
+	notifyMu  sync.Mutex     // guards pending and notifying
+	pending   []notification // stores whose listeners haven't run yet, oldest first
+	notifying bool           // a goroutine is draining pending
+}
+
+// New loads path and starts watching it for changes.
+// If path == "", it uses SECRETS_FILE or the default path.
+// Unlike the package-level API, a failed initial load is returned as an
+// error and no watcher is left running.
+func New(path string, opts ...Option) (*Env, error) {
+	return NewMulti([]string{path}, opts...)
+}
+
+// NewMulti is New for several files loaded in order, later files overriding
+// keys from earlier ones. Files that don't exist yet are skipped with a
+// warning; an error is returned only if none of them could be loaded.
+func NewMulti(paths []string, opts ...Option) (*Env, error) {
+	e := newEnv(opts...)
+	if err := e.start(e.baseContext(), paths, nil); err != nil {
+		e.Stop()
+		return nil, err
+	}
+	return e, nil
+}
+
+func newEnv(opts ...Option) *Env {
+	e := &Env{opts: defaultOptions()}
+	e.fallbackToProcessEnv.Store(true)
+	e.cfg.Store(config{m: map[string]string{}})
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// --------- Public API ----------
+
+// Getenv returns the value for key. If not present, it returns def (if provided) or "".
+func (e *Env) Getenv(key string, def ...string) string {
+	if len(def) == 0 {
+		return e.get(key)
+	}
+	v := e.getFrom(e.snapshot(), key)
+	if v == "" {
+		e.warnDefault(key)
+		return def[0]
+	}
+	return v
+}
+
+// Lookup returns the value for key and whether it is set. A key present in
+// the file reports true even when its value is empty. The process
+// environment is consulted only when fallback is enabled, first if the
+// priority is PriorityEnv.
+func (e *Env) Lookup(key string) (string, bool) {
+	key = e.foldKey(key)
+	if e.opts.priority == PriorityEnv && e.fallbackToProcessEnv.Load() {
+		if v := os.Getenv(key); v != "" {
+			return v, true
+		}
+	}
+	if v, ok := e.snapshot().m[key]; ok {
+		return v, true
+	}
+	if e.fallbackToProcessEnv.Load() {
+		return os.LookupEnv(key)
+	}
+	return "", false
+}
+
+// GetAll returns a copy of every key loaded from the file(s), never nil.
+// Keys that exist only in the process environment are not included.
+func (e *Env) GetAll() map[string]string {
+	return e.snapshot().clone()
+}
+
+// GetenvWithSource is the *Env counterpart of the package-level GetenvWithSource.
+func (e *Env) GetenvWithSource(key string, def ...string) (value, source string) {
+	if v, src := e.resolve(e.snapshot(), key); src != "" {
+		return v, src
+	}
+	if len(def) > 0 {
+		e.warnDefault(key)
+		return def[0], "default"
+	}
+	if v, ok := e.registeredDefault(key); ok {
+		return v, "default"
+	}
+	return "", ""
+}
+
+// GetMany is the *Env counterpart of the package-level GetMany.
+func (e *Env) GetMany(keys ...string) map[string]string {
+	c := e.snapshot()
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		out[k] = e.orDefault(k, e.getFrom(c, k))
+	}
+	return out
+}
+
+// GetWithPrefix is the *Env counterpart of the package-level GetWithPrefix.
+func (e *Env) GetWithPrefix(prefix string) map[string]string {
+	prefix = e.foldKey(prefix)
+	out := make(map[string]string)
+	for k, v := range e.snapshot().m {
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+// Keys is the *Env counterpart of the package-level Keys.
+func (e *Env) Keys() []string {
+	return slices.Sorted(maps.Keys(e.snapshot().m))
+}
+
+// OnReload registers fn to be called after each successful reload with
+// copies of the previous and the new key maps. Callbacks run in
+// registration order on the reload goroutine, never on the watcher loop,
+// and are not called for the initial load. They run after the reload has
+// released its lock, so fn may itself call Reload; reloads still reach the
+// callbacks in order.
+func (e *Env) OnReload(fn func(old, new map[string]string)) {
+	e.addListener(func(old, cur config) {
+		fn(old.clone(), cur.clone())
+	})
+}
+
+// Init starts the watcher on path if it is not running yet.
+// It is a no-op for an Env returned by New.
+func (e *Env) Init(path string) {
+	e.InitMulti(path)
+}
+
+// MustInit is the *Env counterpart of the package-level MustInit.
+func (e *Env) MustInit(path string) {
+	if err := e.start(e.baseContext(), []string{path}, nil); err != nil {
+		panic(fmt.Sprintf("hotenv: initial load of %s failed: %v (check SECRETS_FILE or the path passed to MustInit)", e.Path(), err))
+	}
+}
+
+// InitContext is the *Env counterpart of the package-level InitContext.
+func (e *Env) InitContext(ctx context.Context, path string) {
+	if err := e.start(ctx, []string{path}, nil); err != nil {
+		e.initFailed(err)
+	}
+}
+
+// InitMulti is Init for several files; see NewMulti for the merge rules.
+// It is a no-op if the Env is already running.
+func (e *Env) InitMulti(paths ...string) {
+	if err := e.start(e.baseContext(), paths, nil); err != nil {
+		e.initFailed(err)
+	}
+}
+
+// InitNamespaced is the *Env counterpart of the package-level InitNamespaced.
+func (e *Env) InitNamespaced(files map[string]string) {
+	if err := e.start(e.baseContext(), slices.Sorted(maps.Keys(files)), files); err != nil {
+		e.initFailed(err)
+	}
+}
+
+// Reload is the *Env counterpart of the package-level Reload.
+func (e *Env) Reload() error {
+	e.reloadMu.Lock()
+	defer e.unlockReload()
+	if len(e.paths) == 0 {
+		return errNotStarted
+	}
+	environment, layers, c, err := e.readAll()
+	if err != nil {
+		e.reloadFailed(err)
+		return err
+	}
+	e.environment, e.layers = environment, layers
+	e.reloaded(c)
+	return nil
+}
+
+// Stop stops the background watcher and waits for it to exit. It is safe to
+// call after the watcher's context was cancelled, and more than once.
+func (e *Env) Stop() {
+	e.stopOnce.Do(func() {
+		if e.cancel != nil {
+			e.cancel()
+			<-e.done
+		}
+	})
+}
+
+// Path returns the first file being watched, or "" if the Env has not started.
+func (e *Env) Path() string {
+	if len(e.paths) == 0 {
+		return ""
+	}
+	return e.paths[0]
+}
+
+// Paths returns every file being watched, in precedence order (last wins).
+func (e *Env) Paths() []string {
+	return slices.Clone(e.paths)
+}
+
+// --------- Internals ----------
+
+func (e *Env) snapshot() config {
+	cur, _ := e.cfg.Load().(config)
+	return cur
+}
+
+type listener struct {
+	fn func(old, cur config)
+}
+
+// addListener registers fn to run after every store and returns a func that
+// removes it again. Callers hold reloadMu when they need the registration to
+// be ordered with respect to reloads.
+func (e *Env) addListener(fn func(old, cur config)) (remove func()) {
+	l := &listener{fn: fn}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, l)
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if i := slices.Index(e.listeners, l); i >= 0 {
+			e.listeners = slices.Delete(e.listeners, i, i+1)
+		}
+	}
+}
+
+// notification is one store, or one failed reload, waiting for its
+// listeners to run.
+type notification struct {
+	listeners []*listener
+	old, cur  config
+}
+
+// store swaps in c and queues a notification for the listeners registered
+// now; they run once the caller releases reloadMu through unlockReload.
+// Callers hold reloadMu, so notifications are queued in reload order.
+func (e *Env) store(c config) {
+	old, _ := e.cfg.Swap(c).(config)
+	if e.opts.syncToProcessEnv {
+		e.syncProcessEnv(old, c)
+	}
+	e.mu.Lock()
+	listeners := slices.Clone(e.listeners)
+	e.mu.Unlock()
+	e.enqueue(notification{listeners: listeners, old: old, cur: c})
+	e.notifyWatchers(old, c)
+}
+
+// enqueue adds n to the notifications run by unlockReload. Callers hold
+// reloadMu.
+func (e *Env) enqueue(n notification) {
+	e.notifyMu.Lock()
+	e.pending = append(e.pending, n)
+	e.notifyMu.Unlock()
+}
+
+// unlockReload releases reloadMu and then runs the queued listeners and
+// error handlers with no lock held, so a callback may call Reload,
+// PreviewReload or BindAtomicTo and a slow one doesn't hold up other reloads.
+func (e *Env) unlockReload() {
+	e.reloadMu.Unlock()
+	e.drainNotifications()
+}
+
+// drainNotifications runs the queued listeners. Only one goroutine drains
+// the queue at a time, which keeps callbacks in reload order; if another one
+// is already draining, it delivers what this caller queued.
+func (e *Env) drainNotifications() {
+	e.notifyMu.Lock()
+	if e.notifying {
+		e.notifyMu.Unlock()
+		return
+	}
+	e.notifying = true
+	for len(e.pending) > 0 {
+		n := e.pending[0]
+		e.pending = e.pending[1:]
+		e.notifyMu.Unlock()
+		for _, l := range n.listeners {
+			e.notify(l, n.old, n.cur)
+		}
+		e.notifyMu.Lock()
+	}
+	e.pending = nil
+	e.notifying = false
+	e.notifyMu.Unlock()
+}
+
+// build merges layers in order (later layers win), expands references if
+// interpolation is enabled, keeps only the keys under the key prefix (if
+// any), applies value transforms, and checks the result
+// against the configured limits and validators.
+func (e *Env) build(layers []config) (config, error) {
+	c := layers[0]
+	if len(layers) > 1 {
+		c = config{m: make(map[string]string)}
+		for _, l := range layers {
+			for k, v := range l.m {
+				c.m[k] = v
+			}
+		}
+	}
+	if e.opts.interpolate {
+		var err error
+		if c, err = e.interpolate(c); err != nil {
+			return c, err
+		}
+	}
+	if e.opts.keyPrefix != "" {
+		c = e.stripPrefix(c)
+	}
+	if len(e.opts.transforms) > 0 {
+		c = e.transform(c)
+	}
+	if limit := e.opts.maxConfigBytes; limit > 0 && c.size() > limit {
+		return c, fmt.Errorf("config is %d bytes, exceeds limit of %d", c.size(), limit)
+	}
+	if err := e.validate(c); err != nil {
+		if e.opts.maskedLogging {
+			// validators are user code and may quote the values they reject
+			return c, fmt.Errorf("validation failed: %s", e.scrub(err.Error(), c))
+		}
+		return c, fmt.Errorf("validation failed: %w", err)
+	}
+	return c, nil
+}
+
+// transform returns a copy of c with the registered value transforms
+// applied, in registration order, to the keys present in c. Keys are
+// folded like stored keys, see OptCaseInsensitiveKeys.
+func (e *Env) transform(c config) config {
+	out := config{m: c.clone()}
+	for k, fns := range e.opts.transforms {
+		k = e.foldKey(k)
+		v, ok := out.m[k]
+		if !ok {
+			continue
+		}
+		for _, fn := range fns {
+			v = fn(v)
+		}
+		out.m[k] = v
+	}
+	return out
+}
+
+// stripPrefix keeps the keys starting with the key prefix, without it.
+// Keys made only of the prefix are dropped too.
+func (e *Env) stripPrefix(c config) config {
+	out := config{m: make(map[string]string)}
+	for k, v := range c.m {
+		if name, ok := strings.CutPrefix(k, e.foldKey(e.opts.keyPrefix)); ok && name != "" {
+			out.m[name] = v
+		}
+	}
+	return out
+}
+
+// validate runs the raw validators, then the typed ones, and joins every failure.
+func (e *Env) validate(c config) error {
+	var errs []error
+	for _, fn := range e.opts.validators {
+		if err := fn(c.clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(e.opts.typedValidators) > 0 {
+		v := e.view(c)
+		for _, fn := range e.opts.typedValidators {
+			if err := fn(v); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// source is one file (or directory) contributing to the merged config.
+type source struct {
+	path     string
+	optional bool   // environment overlays may be absent at any time
+	prefix   string // namespace for the source's keys, "" for none
+}
+
+// namespaced returns c with every key prefixed by the source's namespace.
+func (src source) namespaced(c config) config {
+	if src.prefix == "" {
+		return c
+	}
+	out := config{m: make(map[string]string, len(c.m))}
+	for k, v := range c.m {
+		out.m[src.prefix+"_"+k] = v
+	}
+	return out
+}
+
+// resolveSources returns the active environment and the sources to load for
+// it: each configured path, followed by its "<path>.<environment>" overlay
+// when an environment resolver is set and reports a non-empty name, and
+// finally the local override file, if any. An overlay shares the namespace
+// of its path.
+func (e *Env) resolveSources() (string, []source) {
+	var environment string
+	if e.opts.environmentResolver != nil {
+		environment = e.opts.environmentResolver()
+	}
+	srcs := make([]source, 0, len(e.paths))
+	for _, p := range e.paths {
+		prefix := e.foldKey(e.prefixes[p])
+		srcs = append(srcs, source{path: p, prefix: prefix})
+		if environment != "" {
+			srcs = append(srcs, source{path: p + "." + environment, optional: true, prefix: prefix})
+		}
+	}
+	if p := e.opts.localOverride; p != "" {
+		srcs = append(srcs, source{path: p, optional: true})
+	}
+	return environment, srcs
+}
+
+// watchedPaths returns the configured paths plus the local override file.
+func (e *Env) watchedPaths() []string {
+	if p := e.opts.localOverride; p != "" {
+		return append(slices.Clone(e.paths), p)
+	}
+	return e.paths
+}
+
+// readSources loads every source. Missing overlays are skipped; with several
+// configured paths, missing paths are skipped with a warning. It fails only
+// if no configured path could be read.
+func (e *Env) readSources(srcs []source, load func(source) (config, error)) ([]config, error) {
+	layers := make([]config, len(srcs))
+	var firstErr error
+	loaded := 0
+	for i, src := range srcs {
+		layers[i] = config{m: map[string]string{}}
+		c, err := load(src)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				if src.optional {
+					continue
+				}
+				if len(e.paths) > 1 {
+					e.logWarn("file not found, skipping", slog.String("path", src.path))
+					firstErr = cmp.Or(firstErr, err)
+					continue
+				}
+			}
+			return nil, err
+		}
+		layers[i] = src.namespaced(c)
+		if !src.optional {
+			loaded++
+		}
+	}
+	if loaded == 0 {
+		return nil, firstErr
+	}
+	return layers, nil
+}
+
+// reloadAttempts and reloadBackoff bound how long a reload keeps retrying a
+// failed read, e.g. one that lands inside a K8s ..data symlink swap.
+const (
+	reloadAttempts = 3
+	reloadBackoff  = 50 * time.Millisecond
+)
+
+// loadSettled reads src for a reload. The path is resolved through
+// filepath.EvalSymlinks first so a read sees one consistent target, and a
+// failed read is retried with a short backoff before the error is reported.
+// A missing optional source is reported immediately.
+func (e *Env) loadSettled(src source) (config, error) {
+	var c config
+	var err error
+	for attempt := 0; attempt < reloadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * reloadBackoff)
+		}
+		path := src.path
+		if real, rerr := filepath.EvalSymlinks(path); rerr == nil {
+			path = real
+		}
+		if c, err = e.loadSource(path); err == nil {
+			return c, nil
+		}
+		if src.optional && errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	return c, err
+}
+
+// readAll re-reads every source for the current environment and builds the
+// merged config without storing anything. Callers hold reloadMu.
+func (e *Env) readAll() (environment string, layers []config, c config, err error) {
+	environment, srcs := e.resolveSources()
+	if layers, err = e.readSources(srcs, e.loadSettled); err != nil {
+		return "", nil, config{}, err
+	}
+	if c, err = e.build(layers); err != nil {
+		return "", nil, config{}, err
+	}
+	return environment, layers, c, nil
+}
+
+// loadAll performs the initial load of every source and stores the merged result.
+func (e *Env) loadAll() error {
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	environment, srcs := e.resolveSources()
+	e.environment = environment
+	e.layers = make([]config, len(srcs))
+	for i := range e.layers {
+		e.layers[i] = config{m: map[string]string{}}
+	}
+
+	layers, err := e.readSources(srcs, func(src source) (config, error) {
+		return e.loadSource(src.path)
+	})
+	if err != nil {
+		return err
+	}
+	c, err := e.build(layers)
+	if err != nil {
+		return err
+	}
+	e.layers = layers
+	e.cfg.Store(c)
+	e.generation.Store(1)
+	if e.opts.syncToProcessEnv {
+		e.syncProcessEnv(config{}, c)
+	}
+	e.lastReload.Store(time.Now().UnixNano())
+	return nil
+}
+
+// reloaded records a successful reload and stores c. Callers hold reloadMu.
+// A reload that produced exactly the current config, e.g. after a chmod or
+// a touch, is not a change: nothing is stored, logged or notified, and only
+// a previous reload error is cleared.
+func (e *Env) reloaded(c config) {
+	if e.generation.Load() > 0 && maps.Equal(e.snapshot().m, c.m) {
+		e.lastError.Store("")
+		return
+	}
+	if n := e.opts.historySize; n > 0 {
+		e.history.add(newReloadEvent(e.snapshot(), c), n)
+	}
+	e.reloads.Add(1)
+	e.lastReload.Store(time.Now().UnixNano())
+	e.lastError.Store("")
+	e.generation.Add(1)
+	if m := e.opts.metrics; m != nil {
+		m.IncReload()
+	}
+	e.logInfo("reloaded", slog.Int("keys", len(c.m)), e.pathAttr())
+	e.store(c)
+}
+
+// initFailed logs a failed initial load for the Init family. A source that
+// doesn't exist yet is the normal state while a sidecar or init container is
+// still writing it, and the watcher loads it once it appears, so that is
+// only a warning.
+func (e *Env) initFailed(err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		e.logWarn("source not found yet, waiting for it to be created", e.pathAttr(), errAttr(err))
+		return
+	}
+	e.logError("initial load failed, continuing with empty config", e.pathAttr(), errAttr(err))
+}
+
+// reloadFailed records and logs a failed reload and queues its handlers.
+// Callers hold reloadMu.
+func (e *Env) reloadFailed(err error) {
+	e.reloadFailures.Add(1)
+	e.lastFailure.Store(time.Now().UnixNano())
+	if m := e.opts.metrics; m != nil {
+		m.IncReloadFailure()
+	}
+	e.lastError.Store(err.Error())
+	e.reloadErr.Store(&err)
+	e.logError("reload failed", e.pathAttr(), errAttr(err))
+	// like listeners, the handlers run once reloadMu is released
+	if fn := e.opts.reloadErrorHandler; fn != nil && e.opts.reloadErrorPolicy == PolicyKeepLastAndNotify {
+		e.enqueue(notification{listeners: []*listener{{fn: func(_, _ config) { fn(err) }}}})
+	}
+	if fn := e.opts.onSourceMissing; fn != nil && errors.Is(err, fs.ErrNotExist) {
+		e.enqueue(notification{listeners: []*listener{{fn: func(_, _ config) { fn() }}}})
+	}
+}
+
+// syncProcessEnv mirrors the change from old to cur into the process
+// environment, touching only added, changed and removed keys. Callers hold
+// reloadMu, so syncs happen in reload order.
+func (e *Env) syncProcessEnv(old, cur config) {
+	d := diff(old, cur)
+	for k, v := range d.Added {
+		e.setenv(k, v)
+	}
+	for k, v := range d.Changed {
+		e.setenv(k, v[1])
+	}
+	for k := range d.Removed {
+		if err := os.Unsetenv(k); err != nil {
+			e.logWarn("unsetenv failed", slog.String("key", k), errAttr(err))
+		}
+	}
+}
+
+func (e *Env) setenv(key, value string) {
+	if err := os.Setenv(key, value); err != nil {
+		e.logWarn("setenv failed", slog.String("key", key), errAttr(err))
+	}
+}
+
+// notify runs a single listener, recovering from a panic so that one bad
+// callback can neither crash the process nor starve the others.
+func (e *Env) notify(l *listener, old, cur config) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logError("reload callback panicked", slog.Any("panic", r))
+		}
+	}()
+	l.fn(old, cur)
+}
+
+// reloadDir re-reads the sources living in dir and stores the merged result,
+// keeping the current config if a read fails. Sources elsewhere are not
+// re-read, unless the active environment changed, in which case the overlay
+// set changes too, or a file of a multi-file config disappeared; then
+// everything is reloaded as by Reload, which skips missing files.
+func (e *Env) reloadDir(dir string) {
+	e.reloadMu.Lock()
+	defer e.unlockReload()
+
+	environment, srcs := e.resolveSources()
+	full := environment != e.environment
+	if full {
+		e.logInfo("environment changed", slog.String("from", e.environment), slog.String("to", environment))
+	}
+	var layers []config
+	if !full {
+		layers = slices.Clone(e.layers)
+		for i, src := range srcs {
+			if watchDir(src.path) != dir {
+				continue
+			}
+			l, err := e.loadSettled(src)
+			if err != nil {
+				if src.optional && errors.Is(err, fs.ErrNotExist) {
+					layers[i] = config{m: map[string]string{}}
+					continue
+				}
+				if len(e.paths) > 1 && errors.Is(err, fs.ErrNotExist) {
+					full = true
+					break
+				}
+				e.reloadFailed(err)
+				return
+			}
+			layers[i] = src.namespaced(l)
+		}
+	}
+	if full {
+		var err error
+		if layers, err = e.readSources(srcs, e.loadSettled); err != nil {
+			e.reloadFailed(err)
+			return
+		}
+	}
+	c, err := e.build(layers)
+	if err != nil {
+		e.reloadFailed(err)
+		return
+	}
+	e.environment, e.layers = environment, layers
+	e.reloaded(c)
+}
+
+// baseContext returns the context the watcher runs under unless InitContext
+// provides one: the one set by OptContext, or context.Background().
+func (e *Env) baseContext() context.Context {
+	if ctx := e.opts.ctx; ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// fallbackPollInterval is the polling interval used when fsnotify can't
+// watch the configured paths and no OptPollingInterval was given.
+const fallbackPollInterval = 2 * time.Second
+
+// start performs the initial load and launches the watcher, or the polling
+// loop if OptPollingInterval is set or fsnotify is unavailable. Only the
+// first call has an effect. The watcher runs even when the initial load
+// fails, so a file that appears later is still picked up.
+func (e *Env) start(parent context.Context, paths []string, prefixes map[string]string) (err error) {
+	e.startOnce.Do(func() {
+		if len(paths) == 0 || (len(paths) == 1 && paths[0] == "") {
+			if p := os.Getenv("SECRETS_FILE"); p != "" {
+				paths = []string{p}
+			} else {
+				paths = []string{e.opts.defaultPath}
+			}
+		}
+		e.paths = slices.Clone(paths)
+		e.prefixes = maps.Clone(prefixes)
+		// fingerprint before loading, so that if polling is used, a change
+		// racing the initial load causes one extra reload rather than none
+		prints := e.fingerprints()
+		// initial load
+		err = e.loadAll()
+		for attempt := 1; err != nil && attempt <= e.opts.initRetries; attempt++ {
+			e.logWarn("initial load failed, retrying", e.pathAttr(), slog.Int("attempt", attempt), errAttr(err))
+			time.Sleep(e.opts.initRetryDelay)
+			err = e.loadAll()
+		}
+		if err != nil {
+			e.lastError.Store(err.Error())
+		}
+		if e.opts.expvarName != "" {
+			e.publishExpvar(e.opts.expvarName)
+		}
+		ctx, cancel := context.WithCancel(parent)
+		e.cancel = cancel
+		e.done = make(chan struct{})
+		poll := func(d time.Duration) {
+			go func() {
+				defer close(e.done)
+				e.pollAndReload(ctx, d, prints)
+			}()
+		}
+		if d := e.opts.pollInterval; d > 0 {
+			poll(d)
+			return
+		}
+		// start watcher; the watch is registered before start returns so
+		// no change made right after New/Init is missed
+		w, missing, werr := newDirWatcher(e.watchedPaths())
+		if werr != nil {
+			e.logWarn("watch failed, falling back to polling", e.pathAttr(), slog.Duration("interval", fallbackPollInterval), errAttr(werr))
+			poll(fallbackPollInterval)
+			return
+		}
+		go func() {
+			defer close(e.done)
+			defer w.Close()
+			e.watchAndReload(ctx, w, e.opts.debounce, missing)
+		}()
+	})
+	return err
+}
+
+// warnDefault logs, once per key, that a caller-supplied default was used.
+func (e *Env) warnDefault(key string) {
+	if !e.opts.warnOnDefault {
+		return
+	}
+	if _, seen := e.warnedDefaults.LoadOrStore(key, struct{}{}); !seen {
+		e.logWarn("key not found in file or environment, using default (typo in key name?)", slog.String("key", key))
+	}
+}
+
+// get resolves key like getFrom against the current config, falling back
+// to the default registered with SetDefault.
+func (e *Env) get(key string) string {
+	return e.orDefault(key, e.getFrom(e.snapshot(), key))
+}
+
+// getFrom is get against an already loaded config.
+func (e *Env) getFrom(c config, key string) string {
+	v, _ := e.resolve(c, key)
+	return v
+}
+
+// resolve looks key up in c and, if fallback is enabled, the process
+// environment, in the order set by OptEnvPriority. Empty values don't count.
+// It reports the source as "file" or "env", or "" if neither has a value.
+func (e *Env) resolve(c config, key string) (value, source string) {
+	key = e.foldKey(key)
+	envFirst := e.opts.priority == PriorityEnv
+	fallback := e.fallbackToProcessEnv.Load()
+	if fallback && envFirst {
+		if v := os.Getenv(key); v != "" {
+			return v, "env"
+		}
+	}
+	// file-based
+	if v := c.m[key]; v != "" {
+		return v, "file"
+	}
+	// optional process env fallback
+	if fallback && !envFirst {
+		if v := os.Getenv(key); v != "" {
+			return v, "env"
+		}
+	}
+	return "", ""
+}
+
+// foldKey upper-cases key if keys are case-insensitive.
+func (e *Env) foldKey(key string) string {
+	if e.opts.caseInsensitive {
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+// watchDir returns the directory to watch for path: path itself in
+// directory-of-files mode, its parent otherwise.
+func watchDir(path string) string {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return filepath.Clean(path)
+	}
+	return filepath.Dir(path)
+}
+
+// atomicSwapDelay is how long OptAtomicSymlinkMode waits after a source is
+// replaced, removed or renamed for the swap to settle.
+const atomicSwapDelay = 50 * time.Millisecond
+
+// swapDetector returns a func reporting whether a Create, Remove or Rename
+// of name is an atomic swap to be reloaded right away: the replacement of a watched
+// file (or one of its overlays) or of a K8s ..data symlink. It always
+// reports false unless OptAtomicSymlinkMode is set.
+func (e *Env) swapDetector() func(name string) bool {
+	if !e.opts.atomicSymlink {
+		return func(string) bool { return false }
+	}
+	var bases []string
+	for _, p := range e.watchedPaths() {
+		bases = append(bases, filepath.Base(p))
+	}
+	return func(name string) bool {
+		name = filepath.Base(name)
+		if name == "..data" {
+			return true
+		}
+		for _, b := range bases {
+			if name == b || strings.HasPrefix(name, b+".") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// rewatchMinDelay and rewatchMaxDelay bound the backoff between attempts to
+// re-add the watch on a directory that was removed, e.g. by a volume remount.
+const (
+	rewatchMinDelay = 100 * time.Millisecond
+	rewatchMaxDelay = 5 * time.Second
+)
+
+// newDirWatcher watches the directories containing paths. Directories that
+// don't exist yet are returned as missing, for the watch loop to add once
+// they appear.
+func newDirWatcher(paths []string) (w *fsnotify.Watcher, missing []string, err error) {
+	w, err = fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("watcher init failed: %w", err)
+	}
+	for _, p := range paths {
+		dir := watchDir(p)
+		if err := w.Add(dir); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				missing = append(missing, dir)
+				continue
+			}
+			w.Close()
+			return nil, nil, fmt.Errorf("watch add failed: %w", err)
+		}
+	}
+	return w, missing, nil
+}
+
+func (e *Env) watchAndReload(ctx context.Context, w *fsnotify.Watcher, debounce time.Duration, missing []string) {
+	// reloads are per directory: K8s swaps ..data symlinks, so an event
+	// doesn't necessarily name the file itself
+	watched := make(map[string]bool)
+	for _, p := range e.watchedPaths() {
+		watched[watchDir(p)] = true
+	}
+	swapped := e.swapDetector()
+
+	var timerMu sync.Mutex
+	timers := make(map[string]*time.Timer)
+	swapping := make(map[string]bool) // guarded by timerMu
+	trigger := func(dir string) {
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		if swapping[dir] {
+			// a swap reload is about to run and will see this change too
+			return
+		}
+		if t := timers[dir]; t != nil {
+			_ = t.Stop()
+		}
+		timers[dir] = time.AfterFunc(debounce, func() {
+			e.reloadDir(dir)
+		})
+	}
+	// swap reloads dir shortly after an atomic swap, see OptAtomicSymlinkMode
+	swap := func(dir string) {
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		if swapping[dir] {
+			return
+		}
+		if t := timers[dir]; t != nil {
+			_ = t.Stop()
+		}
+		swapping[dir] = true
+		timers[dir] = time.AfterFunc(atomicSwapDelay, func() {
+			timerMu.Lock()
+			delete(swapping, dir)
+			timerMu.Unlock()
+			if err := w.Add(dir); err != nil {
+				e.logWarn("re-adding watch failed", slog.String("dir", dir), errAttr(err))
+			}
+			e.reloadDir(dir)
+		})
+	}
+
+	// a removed or renamed directory takes its watch with it, and one that
+	// didn't exist at start was never watched; rewatch adds it in the
+	// background once it exists
+	ctx, cancel := context.WithCancel(ctx)
+	var rewatches sync.WaitGroup
+	rewatching := make(map[string]bool) // guarded by timerMu
+	rewatch := func(dir, why string) {
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		if rewatching[dir] {
+			return
+		}
+		rewatching[dir] = true
+		e.logWarn(why, slog.String("dir", dir))
+		rewatches.Go(func() {
+			defer func() {
+				timerMu.Lock()
+				delete(rewatching, dir)
+				timerMu.Unlock()
+			}()
+			for delay := rewatchMinDelay; ; delay = min(2*delay, rewatchMaxDelay) {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				if err := w.Add(dir); err == nil {
+					e.logInfo("watch added", slog.String("dir", dir))
+					trigger(dir)
+					return
+				}
+			}
+		})
+	}
+
+	// don't let a pending reload fire after Stop
+	defer func() {
+		cancel()
+		rewatches.Wait()
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		for _, t := range timers {
+			_ = t.Stop()
+		}
+	}()
+
+	for _, dir := range missing {
+		rewatch(dir, "directory missing, watching for it")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && watched[ev.Name] {
+				rewatch(ev.Name, "watch lost, re-adding")
+				continue
+			}
+			// a rename onto the name shows up as a Create of it
+			if ev.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 && swapped(ev.Name) {
+				if dir := filepath.Dir(ev.Name); watched[dir] {
+					swap(dir)
+					continue
+				}
+			}
+			// Any change in dir (K8s does atomic swaps) -> reload
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename|fsnotify.Chmod) != 0 {
+				if dir := filepath.Dir(ev.Name); watched[dir] {
+					trigger(dir)
+				}
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			e.watchErrors.Add(1)
+			if m := e.opts.metrics; m != nil {
+				m.IncWatchError()
+			}
+			e.logError("watch error", errAttr(err))
+		}
+	}
+}
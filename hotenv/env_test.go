@@ -0,0 +1,200 @@
+package hotenv
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startEnv starts an Env on path whose watcher won't fire during a test,
+// so reloads happen only when the test calls Reload.
+func startEnv(t *testing.T, path string, opts ...Option) *Env {
+	t.Helper()
+	e, err := New(path, append([]Option{OptPollingInterval(time.Hour)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Stop)
+	return e
+}
+
+// rewrite replaces the content of path.
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadFromCallback(t *testing.T) {
+	path := writeFile(t, ".env", "A=1\n")
+	e, err := New(path, OptPollingInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+
+	var mu sync.Mutex
+	var seen []string
+	e.OnReload(func(_, cur map[string]string) {
+		mu.Lock()
+		seen = append(seen, cur["A"])
+		mu.Unlock()
+		if cur["A"] == "2" {
+			// a callback may reload and preview without deadlocking
+			if err := os.WriteFile(path, []byte("A=3\n"), 0o600); err != nil {
+				t.Error(err)
+			}
+			if _, err := e.PreviewReload(); err != nil {
+				t.Error(err)
+			}
+			if err := e.Reload(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		if err := os.WriteFile(path, []byte("A=2\n"), 0o600); err != nil {
+			done <- err
+			return
+		}
+		done <- e.Reload()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload from an OnReload callback deadlocked")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"2", "3"}; !slices.Equal(seen, want) {
+		t.Errorf("callbacks saw %q, want %q", seen, want)
+	}
+}
+
+func TestMaxConfigBytes(t *testing.T) {
+	if _, err := New(writeFile(t, ".env", "KEY=0123456789\n"), OptMaxConfigBytes(8)); err == nil {
+		t.Error("New: want an error for an initial config over the limit")
+	}
+
+	path := writeFile(t, ".env", "KEY=small\n")
+	e := startEnv(t, path, OptMaxConfigBytes(32))
+	rewrite(t, path, "KEY="+strings.Repeat("x", 64)+"\n")
+	if err := e.Reload(); err == nil {
+		t.Error("Reload: want an error for a config over the limit")
+	}
+	if got := e.Getenv("KEY"); got != "small" {
+		t.Errorf("Getenv after a rejected reload = %q, want the last good value", got)
+	}
+}
+
+func TestReloadErrorPolicy(t *testing.T) {
+	for _, policy := range []ReloadErrorPolicy{PolicyKeepLast, PolicyKeepLastAndNotify} {
+		path := writeFile(t, "secrets.json", `{"DB_PASSWORD": "old"}`)
+		var handled []error
+		e := startEnv(t, path, OptReloadErrorPolicy(policy), OptReloadErrorHandler(func(err error) {
+			handled = append(handled, err)
+		}))
+
+		rewrite(t, path, `{"DB_PASSWORD": "new"`)
+		if err := e.Reload(); err == nil {
+			t.Errorf("policy %v: want an error for a corrupt file", policy)
+		}
+		if got := e.Getenv("DB_PASSWORD"); got != "old" {
+			t.Errorf("policy %v: Getenv after a failed reload = %q, want %q", policy, got, "old")
+		}
+		if want := policy == PolicyKeepLastAndNotify; (len(handled) == 1) != want {
+			t.Errorf("policy %v: handler called %d times", policy, len(handled))
+		}
+	}
+}
+
+func TestValueTransformCaseInsensitive(t *testing.T) {
+	e := startEnv(t, writeFile(t, ".env", "REGION=EU-West-1\n"), OptCaseInsensitiveKeys(true),
+		OptValueTransform("region", strings.ToLower))
+	if got := e.Getenv("region"); got != "eu-west-1" {
+		t.Errorf("Getenv = %q, want the transform applied", got)
+	}
+}
+
+func TestGetManyConsistentAcrossReloads(t *testing.T) {
+	path := writeFile(t, ".env", "A=0\nB=0\n")
+	e := startEnv(t, path)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 200; i++ {
+			// an atomic write, so each reload reads one whole version
+			if err := writeFileAtomic(path, fmt.Appendf(nil, "A=%d\nB=%d\n", i, i)); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := e.Reload(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if m := e.GetMany("A", "B"); m["A"] != m["B"] {
+			t.Errorf("GetMany mixed two configs: %v", m)
+			<-done
+			return
+		}
+	}
+}
+
+func TestReloadFromErrorHandlers(t *testing.T) {
+	path := writeFile(t, "secrets.json", `{"A": "1"}`)
+	var e *Env
+	reentrant := func() {
+		if _, err := e.PreviewReload(); err == nil {
+			t.Error("PreviewReload of a broken file succeeded")
+		}
+		BindAtomicTo(e, new(atomic.Pointer[string]), func(m map[string]string) (*string, error) {
+			v := m["A"]
+			return &v, nil
+		})
+	}
+	e = startEnv(t, path, OptReloadErrorPolicy(PolicyKeepLastAndNotify),
+		OptReloadErrorHandler(func(error) { reentrant() }),
+		OptOnSourceMissing(reentrant))
+
+	for _, broken := range []func(){
+		func() { rewrite(t, path, `{"A": `) },
+		func() {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+		},
+	} {
+		broken()
+		done := make(chan error, 1)
+		go func() { done <- e.Reload() }()
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Error("want a reload error")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("a reload error handler calling back into the Env deadlocked")
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package hotenv
+
+import "flag"
+
+// GetenvFlag returns the value of the flag name if it was set on the command
+// line, and otherwise Getenv(key, def), so that flags override the file and
+// the file overrides def. Call it after fs.Parse; a nil fs means
+// flag.CommandLine. Only flags set explicitly win, so a flag's own default
+// never masks the file. An empty def means none, so a default registered
+// with SetDefault applies.
+func GetenvFlag(fs *flag.FlagSet, name, key, def string) string {
+	ensureStarted("")
+	return std().GetenvFlag(fs, name, key, def)
+}
+
+// GetenvFlag is the *Env counterpart of the package-level GetenvFlag.
+func (e *Env) GetenvFlag(fs *flag.FlagSet, name, key, def string) string {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	var v string
+	set := false
+	// Visit only walks flags that were set
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			v, set = f.Value.String(), true
+		}
+	})
+	if set {
+		return v
+	}
+	if def == "" {
+		return e.Getenv(key)
+	}
+	return e.Getenv(key, def)
+}
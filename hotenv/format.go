@@ -0,0 +1,234 @@
+package hotenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how a file is parsed; see OptFormat.
+type Format int
+
+const (
+	// FormatAuto picks the format from the file name: ".json" files are
+	// parsed as JSON, ".yaml" and ".yml" files as YAML, everything else as
+	// .env. This is the default.
+	FormatAuto Format = iota
+	// FormatDotenv parses KEY=VALUE lines.
+	FormatDotenv
+	// FormatJSON parses a JSON object, as written by AWS Secrets Manager or
+	// the Vault agent: {"DB_PASSWORD": "s3cr3t", "PORT": 5432}. Nested
+	// objects are flattened; see OptFormat.
+	FormatJSON
+	// FormatYAML parses a YAML mapping, flattened like FormatJSON.
+	FormatYAML
+)
+
+// --------- Internals ----------
+
+// formatFor resolves FormatAuto for path. Environment overlays keep the
+// format of their base file, so "secrets.json.production" is JSON too.
+func (e *Env) formatFor(path string) Format {
+	if e.opts.format != FormatAuto {
+		return e.opts.format
+	}
+	name := filepath.Base(path)
+	for _, f := range []struct {
+		ext    string
+		format Format
+	}{{".json", FormatJSON}, {".yaml", FormatYAML}, {".yml", FormatYAML}} {
+		if strings.HasSuffix(name, f.ext) || strings.Contains(name, f.ext+".") {
+			return f.format
+		}
+	}
+	return FormatDotenv
+}
+
+// loadStructuredFile reads a JSON or YAML file and flattens it into keys
+// (see flattener). Decoding errors quote offsets, lines and types, never
+// the offending text.
+func (e *Env) loadStructuredFile(path string, format Format) (config, error) {
+	out := make(map[string]string)
+	b, err := e.readFile(path)
+	if err != nil {
+		return config{m: out}, err
+	}
+	f := &flattener{e: e, out: out}
+	if format == FormatYAML {
+		err = f.yamlDocument(b)
+	} else {
+		err = f.jsonDocument(b)
+	}
+	if err != nil {
+		return config{m: map[string]string{}}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config{m: out}, nil
+}
+
+// flattener turns a tree of objects into keys. Top-level names that hold a
+// value are kept as written, so a flat {"db_password": "x"} yields
+// db_password (see OptCaseInsensitiveKeys). Nested names become
+// environment-style keys: they are joined with "_", upper-cased, and "." and
+// "-" become "_", so {"db": {"host": "x"}} becomes DB_HOST=x. Strings are taken as they are,
+// numbers and booleans as written in the file, and null as "". An array of
+// scalars becomes one comma-separated value (see GetSlice); any other array
+// is flattened by index, as in SERVERS_0_HOST.
+type flattener struct {
+	e   *Env
+	out map[string]string
+}
+
+var keySeparators = strings.NewReplacer(".", "_", "-", "_")
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return envKey(parent) + "_" + envKey(name)
+}
+
+// envKey upper-cases name and turns "." and "-" into "_".
+func envKey(name string) string {
+	return strings.ToUpper(keySeparators.Replace(name))
+}
+
+func (f *flattener) set(key, value string) error {
+	key = f.e.foldKey(key)
+	if _, dup := f.out[key]; dup {
+		return fmt.Errorf("key %s is defined more than once", key)
+	}
+	f.out[key] = value
+	return nil
+}
+
+func (f *flattener) jsonDocument(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) == 0 || b[0] != '{' {
+		return errors.New("top-level JSON value must be an object")
+	}
+	return f.json("", b)
+}
+
+func (f *flattener) json(key string, msg json.RawMessage) error {
+	msg = bytes.TrimSpace(msg)
+	switch {
+	case len(msg) > 0 && msg[0] == '{':
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return err
+		}
+		for k, v := range m {
+			if err := f.json(joinKey(key, k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case len(msg) > 0 && msg[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(msg, &items); err != nil {
+			return err
+		}
+		var list []string
+		for _, it := range items {
+			if it = bytes.TrimSpace(it); len(it) > 0 && (it[0] == '{' || it[0] == '[') {
+				list = nil
+				break
+			}
+			v, err := jsonScalar(it)
+			if err != nil {
+				return err
+			}
+			list = append(list, v)
+		}
+		if len(list) == len(items) {
+			return f.set(key, strings.Join(list, ","))
+		}
+		for i, it := range items {
+			if err := f.json(joinKey(key, strconv.Itoa(i)), it); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	v, err := jsonScalar(msg)
+	if err != nil {
+		return err
+	}
+	return f.set(key, v)
+}
+
+// jsonScalar renders a JSON string, number, boolean or null as a value.
+func jsonScalar(msg json.RawMessage) (string, error) {
+	switch {
+	case len(msg) == 0 || string(msg) == "null":
+		return "", nil
+	case msg[0] == '"':
+		var s string
+		err := json.Unmarshal(msg, &s)
+		return s, err
+	}
+	// numbers and booleans, exactly as written
+	return string(msg), nil
+}
+
+func (f *flattener) yamlDocument(b []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		// empty file
+		return nil
+	}
+	if root := doc.Content[0]; root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: top-level YAML value must be a mapping", root.Line)
+	}
+	return f.yaml("", doc.Content[0])
+}
+
+func (f *flattener) yaml(key string, n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := f.yaml(joinKey(key, n.Content[i].Value), n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		for _, it := range n.Content {
+			if it.Kind != yaml.ScalarNode {
+				list = nil
+				break
+			}
+			list = append(list, yamlScalar(it))
+		}
+		if len(list) == len(n.Content) {
+			return f.set(key, strings.Join(list, ","))
+		}
+		for i, it := range n.Content {
+			if err := f.yaml(joinKey(key, strconv.Itoa(i)), it); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.set(key, yamlScalar(n))
+}
+
+// yamlScalar returns a scalar as written, with null as "".
+func yamlScalar(n *yaml.Node) string {
+	if n.Tag == "!!null" {
+		return ""
+	}
+	return n.Value
+}
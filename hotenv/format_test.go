@@ -0,0 +1,38 @@
+package hotenv
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestStructuredKeys(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		opts                []Option
+		want                map[string]string
+	}{
+		{"flat JSON kept as written", "secrets.json", `{"db_password": "s3cr3t", "api.token": "t"}`, nil,
+			map[string]string{"db_password": "s3cr3t", "api.token": "t"}},
+		{"nested JSON flattened", "config.json", `{"db": {"host": "x", "max-conns": 5}, "servers": [{"host": "a"}]}`, nil,
+			map[string]string{"DB_HOST": "x", "DB_MAX_CONNS": "5", "SERVERS_0_HOST": "a"}},
+		{"flat YAML kept as written", "secrets.yaml", "db_password: s3cr3t\nhosts: [a, b]\n", nil,
+			map[string]string{"db_password": "s3cr3t", "hosts": "a,b"}},
+		{"nested YAML flattened", "config.yml", "db:\n  host: x\n", nil,
+			map[string]string{"DB_HOST": "x"}},
+		{"case-insensitive", "secrets.json", `{"db_password": "s3cr3t"}`, []Option{OptCaseInsensitiveKeys(true)},
+			map[string]string{"DB_PASSWORD": "s3cr3t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(tt.opts...)
+			path := writeFile(t, tt.file, tt.content)
+			c, err := e.loadStructuredFile(path, e.formatFor(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(c.m, tt.want) {
+				t.Errorf("got %q, want %q", c.m, tt.want)
+			}
+		})
+	}
+}
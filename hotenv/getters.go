@@ -0,0 +1,321 @@
+package hotenv
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --------- Public API ----------
+
+// ErrKeyNotFound is returned by getters that report errors when the key is
+// neither in the file(s) nor, with fallback enabled, the process environment.
+var ErrKeyNotFound = errors.New("hotenv: key not found")
+
+// GetInt returns key parsed as an int. If the key is missing or its value
+// can't be parsed, it returns def (if provided) or 0.
+func GetInt(key string, def ...int) int {
+	ensureStarted("")
+	return std().GetInt(key, def...)
+}
+
+// GetBool returns key parsed as a bool. "1", "true", "yes" and "on" are true,
+// "0", "false", "no" and "off" are false (case-insensitive). If the key is
+// missing or has any other value, it returns def (if provided) or false.
+func GetBool(key string, def ...bool) bool {
+	ensureStarted("")
+	return std().GetBool(key, def...)
+}
+
+// GetInt64 returns key parsed as an int64. If the key is missing or its value
+// can't be parsed, it returns def (if provided) or 0.
+func GetInt64(key string, def ...int64) int64 {
+	ensureStarted("")
+	return std().GetInt64(key, def...)
+}
+
+// GetUint returns key parsed as a uint. If the key is missing or its value
+// can't be parsed (negative numbers included), it returns def (if provided) or 0.
+func GetUint(key string, def ...uint) uint {
+	ensureStarted("")
+	return std().GetUint(key, def...)
+}
+
+// FlagState reports a boolean flag together with whether it was set
+// explicitly, for layered configs where an explicit "0" must override a
+// default-on. With explicit == false (key missing, empty or not a valid
+// bool) the caller's own default applies:
+//
+//	on, explicit := hotenv.FlagState("FEATURE_X")
+//	if !explicit {
+//		on = defaultOn
+//	}
+func FlagState(key string) (enabled, explicit bool) {
+	ensureStarted("")
+	return std().FlagState(key)
+}
+
+// GetFloat returns key parsed as a float64. If the key is missing or its value
+// can't be parsed, it returns def (if provided) or 0.
+func GetFloat(key string, def ...float64) float64 {
+	ensureStarted("")
+	return std().GetFloat(key, def...)
+}
+
+// GetFloat64 is an alias for GetFloat.
+func GetFloat64(key string, def ...float64) float64 {
+	return GetFloat(key, def...)
+}
+
+// GetDuration returns key parsed with time.ParseDuration. If the key is missing
+// or its value can't be parsed, it returns def (if provided) or 0.
+func GetDuration(key string, def ...time.Duration) time.Duration {
+	ensureStarted("")
+	return std().GetDuration(key, def...)
+}
+
+// GetSlice splits the value of key on sep ("" means ","), trims whitespace
+// around each element and drops empty ones. If the key is missing, it returns
+// def (if provided) or an empty, non-nil slice.
+func GetSlice(key string, sep string, def ...[]string) []string {
+	ensureStarted("")
+	return std().GetSlice(key, sep, def...)
+}
+
+// GetStringSlice is an alias for GetSlice.
+func GetStringSlice(key string, sep string, def ...[]string) []string {
+	return GetSlice(key, sep, def...)
+}
+
+// GetBase64 returns key decoded as standard base64, as Kubernetes encodes
+// secret data; URL-safe base64 is tried if that fails, and both accept
+// unpadded input. A missing key returns ErrKeyNotFound.
+func GetBase64(key string) ([]byte, error) {
+	ensureStarted("")
+	return std().GetBase64(key)
+}
+
+// GetIntE is GetInt for callers that want the error, e.g. a config check
+// command: a missing or empty key returns ErrKeyNotFound, and an invalid
+// value an error wrapping strconv.ErrSyntax or strconv.ErrRange. Errors name
+// the key but never include the value.
+func GetIntE(key string) (int, error) {
+	ensureStarted("")
+	return std().GetIntE(key)
+}
+
+// GetBoolE is GetBool for callers that want the error; see GetIntE.
+func GetBoolE(key string) (bool, error) {
+	ensureStarted("")
+	return std().GetBoolE(key)
+}
+
+// GetDurationE is GetDuration for callers that want the error; see GetIntE.
+func GetDurationE(key string) (time.Duration, error) {
+	ensureStarted("")
+	return std().GetDurationE(key)
+}
+
+// GetInt is the *Env counterpart of the package-level GetInt.
+func (e *Env) GetInt(key string, def ...int) int {
+	return getTyped(e, key, "int", strconv.Atoi, def)
+}
+
+// GetInt64 is the *Env counterpart of the package-level GetInt64.
+func (e *Env) GetInt64(key string, def ...int64) int64 {
+	return getTyped(e, key, "int64", parseInt64, def)
+}
+
+// GetUint is the *Env counterpart of the package-level GetUint.
+func (e *Env) GetUint(key string, def ...uint) uint {
+	return getTyped(e, key, "uint", parseUint, def)
+}
+
+// GetBool is the *Env counterpart of the package-level GetBool.
+func (e *Env) GetBool(key string, def ...bool) bool {
+	return getTyped(e, key, "bool", parseBool, def)
+}
+
+// FlagState is the *Env counterpart of the package-level FlagState.
+func (e *Env) FlagState(key string) (enabled, explicit bool) {
+	v, ok := e.Lookup(key)
+	if v = strings.TrimSpace(v); !ok || v == "" {
+		return false, false
+	}
+	b, err := parseBool(v)
+	if err != nil {
+		e.logWarn("invalid value, using default", slog.String("key", key), slog.String("type", "bool"))
+		return false, false
+	}
+	return b, true
+}
+
+// GetFloat is the *Env counterpart of the package-level GetFloat.
+func (e *Env) GetFloat(key string, def ...float64) float64 {
+	return getTyped(e, key, "float", parseFloat, def)
+}
+
+// GetFloat64 is an alias for GetFloat.
+func (e *Env) GetFloat64(key string, def ...float64) float64 {
+	return e.GetFloat(key, def...)
+}
+
+// GetDuration is the *Env counterpart of the package-level GetDuration.
+func (e *Env) GetDuration(key string, def ...time.Duration) time.Duration {
+	return getTyped(e, key, "duration", time.ParseDuration, def)
+}
+
+// GetIntE is the *Env counterpart of the package-level GetIntE.
+func (e *Env) GetIntE(key string) (int, error) {
+	return getTypedE(e, key, "int", strconv.Atoi)
+}
+
+// GetBoolE is the *Env counterpart of the package-level GetBoolE.
+func (e *Env) GetBoolE(key string) (bool, error) {
+	return getTypedE(e, key, "bool", parseBool)
+}
+
+// GetDurationE is the *Env counterpart of the package-level GetDurationE.
+func (e *Env) GetDurationE(key string) (time.Duration, error) {
+	return getTypedE(e, key, "duration", time.ParseDuration)
+}
+
+// GetSlice is the *Env counterpart of the package-level GetSlice.
+func (e *Env) GetSlice(key string, sep string, def ...[]string) []string {
+	v := e.getFrom(e.snapshot(), key)
+	if len(def) == 0 {
+		v = e.orDefault(key, v)
+	}
+	if v == "" {
+		if len(def) > 0 {
+			e.warnDefault(key)
+			return def[0]
+		}
+		return []string{}
+	}
+	return splitList(v, sep)
+}
+
+// GetStringSlice is an alias for GetSlice.
+func (e *Env) GetStringSlice(key string, sep string, def ...[]string) []string {
+	return e.GetSlice(key, sep, def...)
+}
+
+// GetBase64 is the *Env counterpart of the package-level GetBase64.
+func (e *Env) GetBase64(key string) ([]byte, error) {
+	v, ok := e.Lookup(key)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	b, err := decodeBase64(strings.TrimSpace(v))
+	if err != nil {
+		return nil, fmt.Errorf("hotenv: %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// --------- Internals ----------
+
+// getTyped reads key through the regular lookup path and parses it. A value
+// that fails to parse is logged (without the value itself) and def is used,
+// so a typo in the file can't take the service down.
+func getTyped[T any](e *Env, key, kind string, parse func(string) (T, error), def []T) T {
+	var fallback T
+	v := e.getFrom(e.snapshot(), key)
+	if len(def) > 0 {
+		fallback = def[0]
+	} else {
+		v = e.orDefault(key, v)
+	}
+	if v == "" {
+		if len(def) > 0 {
+			e.warnDefault(key)
+		}
+		return fallback
+	}
+	out, err := parse(strings.TrimSpace(v))
+	if err != nil {
+		e.logWarn("invalid value, using default", slog.String("key", key), slog.String("type", kind))
+		return fallback
+	}
+	return out
+}
+
+// getTypedE is getTyped reporting errors instead of falling back to a
+// default; a default registered with SetDefault still counts as a value.
+// An empty value is missing, as it is for getTyped. The parse error is not
+// wrapped as is, since strconv and time quote the input; only a strconv
+// sentinel survives.
+func getTypedE[T any](e *Env, key, kind string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	v := e.get(key)
+	if v == "" {
+		return zero, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	out, err := parse(strings.TrimSpace(v))
+	if err != nil {
+		var ne *strconv.NumError
+		if errors.As(err, &ne) {
+			return zero, fmt.Errorf("hotenv: %s: invalid %s: %w", key, kind, ne.Err)
+		}
+		return zero, fmt.Errorf("hotenv: %s: invalid %s", key, kind)
+	}
+	return out, nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid bool %q", s)
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseUint(s string) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, strconv.IntSize)
+	return uint(n), err
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// splitList splits s on sep ("" means ","), trimming elements and dropping
+// empty ones. The result is never nil.
+func splitList(s, sep string) []string {
+	if sep == "" {
+		sep = ","
+	}
+	out := []string{}
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// decodeBase64 tries the standard alphabet first and falls back to the
+// URL-safe one, padded or not. The error reports the standard-alphabet failure.
+func decodeBase64(s string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err == nil {
+		return b, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, uerr := enc.DecodeString(s); uerr == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64: %w", err)
+}
@@ -0,0 +1,33 @@
+package hotenv
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestGetStringSlice(t *testing.T) {
+	e := startEnv(t, writeFile(t, ".env", "HOSTS=a, b,,c\n"))
+	if got, want := e.GetStringSlice("HOSTS", ","), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("GetStringSlice = %q, want %q", got, want)
+	}
+	if got, want := e.GetStringSlice("MISSING", ",", []string{"x"}), []string{"x"}; !slices.Equal(got, want) {
+		t.Errorf("GetStringSlice default = %q, want %q", got, want)
+	}
+}
+
+func TestGetIntE(t *testing.T) {
+	e := startEnv(t, writeFile(t, ".env", "PORT=\nN=42\nBAD=4x2\n"))
+	for _, key := range []string{"PORT", "MISSING"} {
+		if _, err := e.GetIntE(key); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("GetIntE(%s): %v, want ErrKeyNotFound", key, err)
+		}
+	}
+	if n, err := e.GetIntE("N"); err != nil || n != 42 {
+		t.Errorf("GetIntE(N) = %d, %v", n, err)
+	}
+	if _, err := e.GetIntE("BAD"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("GetIntE(BAD): %v, want strconv.ErrSyntax", err)
+	}
+}
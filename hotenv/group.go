@@ -0,0 +1,82 @@
+package hotenv
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// groupMu serializes the locking of members in ReloadAll so that groups
+// sharing members can't deadlock while acquiring several reload locks.
+var groupMu sync.Mutex
+
+// Group reloads several Envs as a unit, for coordinated changes that span
+// multiple files.
+type Group struct {
+	envs []*Env
+}
+
+// NewGroup returns a Group over envs. The Envs keep their own watchers;
+// the Group only adds transactional reloads on top. Duplicates are ignored.
+func NewGroup(envs ...*Env) *Group {
+	g := &Group{}
+	for _, e := range envs {
+		if !slices.Contains(g.envs, e) {
+			g.envs = append(g.envs, e)
+		}
+	}
+	return g
+}
+
+// ReloadAll re-reads every member. Only if all of them load and validate
+// successfully are the new configs stored; otherwise every member keeps its
+// current config and the joined errors are returned. Watcher-driven reloads
+// of the members are held off for the duration, so no member changes between
+// the read and the swap.
+func (g *Group) ReloadAll() error {
+	groupMu.Lock()
+	for _, e := range g.envs {
+		e.reloadMu.Lock()
+	}
+	groupMu.Unlock()
+	// release every member before running any callback, so callbacks may
+	// reload members or the group itself
+	defer func() {
+		for _, e := range g.envs {
+			e.reloadMu.Unlock()
+		}
+		for _, e := range g.envs {
+			e.drainNotifications()
+		}
+	}()
+
+	type pending struct {
+		environment string
+		layers      []config
+		c           config
+	}
+	next := make([]pending, len(g.envs))
+	var errs []error
+	for i, e := range g.envs {
+		if len(e.paths) == 0 {
+			errs = append(errs, fmt.Errorf("member %d: %w", i, errNotStarted))
+			continue
+		}
+		environment, layers, c, err := e.readAll()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Path(), err))
+			continue
+		}
+		next[i] = pending{environment: environment, layers: layers, c: c}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for i, e := range g.envs {
+		e.environment, e.layers = next[i].environment, next[i].layers
+		e.reloaded(next[i].c)
+	}
+	return nil
+}
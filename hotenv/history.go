@@ -0,0 +1,42 @@
+package hotenv
+
+import "sync"
+
+// history is a bounded ring of the most recent reload events.
+type history struct {
+	mu     sync.Mutex
+	events []ReloadEvent
+	next   int // index of the oldest event once the ring is full
+}
+
+func (h *history) add(ev ReloadEvent, size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.events) < size {
+		h.events = append(h.events, ev)
+		return
+	}
+	h.events[h.next] = ev
+	h.next = (h.next + 1) % size
+}
+
+// list returns the events oldest first.
+func (h *history) list() []ReloadEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]ReloadEvent, 0, len(h.events))
+	out = append(out, h.events[h.next:]...)
+	return append(out, h.events[:h.next]...)
+}
+
+// History returns the most recent reloads of the default config, oldest
+// first. It is empty unless WithHistorySize was set.
+func History() []ReloadEvent {
+	return std().History()
+}
+
+// History returns the most recent reloads of e, oldest first, by key name
+// only. It is empty unless OptHistorySize was set.
+func (e *Env) History() []ReloadEvent {
+	return e.history.list()
+}
@@ -2,48 +2,128 @@ package hotenv
 
 import (
 	"bufio"
+	"bytes"
 	"context"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/fsnotify/fsnotify"
 )
 
 type config struct {
 	m map[string]string
 }
 
-var (
-	cfg        atomic.Value // holds config
-	initOnce   sync.Once
-	stopOnce   sync.Once
-	cancelFunc context.CancelFunc
+// size approximates the memory held by c as the sum of key and value lengths.
+func (c config) size() int {
+	n := 0
+	for k, v := range c.m {
+		n += len(k) + len(v)
+	}
+	return n
+}
 
-	// defaults
-	defaultPath     = "/app/secrets/.env"
-	defaultDebounce = 800 * time.Millisecond
+// clone returns a copy of the key map that callers are free to modify.
+func (c config) clone() map[string]string {
+	out := make(map[string]string, len(c.m))
+	for k, v := range c.m {
+		out[k] = v
+	}
+	return out
+}
 
-	// options (set before first Get/Init)
-	optFallbackToProcessEnv atomic.Bool // default true
-	optLogger               = log.Printf
-)
+// stdEnv holds the default Env behind the package-level API; Reset swaps it.
+var stdEnv atomic.Pointer[Env]
+
+func init() {
+	stdEnv.Store(newEnv())
+}
+
+func std() *Env {
+	return stdEnv.Load()
+}
 
 // --------- Public API ----------
 
 // Getenv returns the value for key. If not present, it returns def (if provided) or "".
-// The first call lazily starts a watcher on SECRETS_FILE (or /secrets/.env).
+// The first call lazily starts a watcher on SECRETS_FILE (or /app/secrets/.env).
+//
+// A non-empty value is looked up in this order:
+//  1. the file(s), or the process environment first with PriorityEnv
+//  2. the process environment, while fallback is enabled
+//  3. def, if provided
+//  4. the default registered with SetDefault or WithDefaults
+//
+// The typed getters follow the same order.
 func Getenv(key string, def ...string) string {
 	ensureStarted("")
-	v := get(key)
-	if v == "" && len(def) > 0 {
-		return def[0]
-	}
-	return v
+	return std().Getenv(key, def...)
+}
+
+// Lookup returns the value for key and whether it is set, so an empty value
+// (KEY=) can be told apart from a missing key. The file is checked first,
+// then the process environment if fallback is enabled.
+func Lookup(key string) (string, bool) {
+	ensureStarted("")
+	return std().Lookup(key)
+}
+
+// GetAll returns a copy of every key currently loaded from the file(s),
+// never nil. Keys visible only in the process environment are not included,
+// even when fallback is enabled. The copy is safe to range over and modify,
+// and it is taken from a single config, so unlike Getenv in a loop it never
+// mixes values from before and after a reload.
+func GetAll() map[string]string {
+	ensureStarted("")
+	return std().GetAll()
+}
+
+// GetenvWithSource is Getenv that also reports where the value came from:
+// "file", "env" (the process environment, when fallback is enabled) or
+// "default". If the key is found nowhere and no default is given, both
+// results are "".
+//
+//	v, src := hotenv.GetenvWithSource("DB_URL")
+//	log.Printf("DB_URL set [source=%s]", src)
+func GetenvWithSource(key string, def ...string) (value, source string) {
+	ensureStarted("")
+	return std().GetenvWithSource(key, def...)
+}
+
+// GetMany reads several keys from the same config, so values that belong
+// together (DB_HOST and DB_PORT) can't straddle a reload. Each key is looked
+// up like Getenv, the process-env fallback included, and every requested key
+// has an entry; missing ones map to "".
+func GetMany(keys ...string) map[string]string {
+	ensureStarted("")
+	return std().GetMany(keys...)
+}
+
+// GetWithPrefix returns every key starting with prefix, with the prefix
+// removed (REDIS_HOST becomes HOST for "REDIS_"), read from a single config.
+// Like GetAll, it covers keys from the file(s) only, never the process
+// environment. The result is never nil.
+func GetWithPrefix(prefix string) map[string]string {
+	ensureStarted("")
+	return std().GetWithPrefix(prefix)
+}
+
+// Keys returns the sorted names of every key loaded from the file(s). Like
+// GetAll, it doesn't include keys only present in the process environment.
+func Keys() []string {
+	ensureStarted("")
+	return std().Keys()
+}
+
+// OnReload registers fn to be called after each successful reload that
+// changed the config, with copies of the previous and the new key maps. It
+// may be called before Init.
+func OnReload(fn func(old, new map[string]string)) {
+	std().OnReload(fn)
 }
 
 // Init starts the watcher explicitly with a given path. Call at program start if you prefer.
@@ -53,184 +133,448 @@ func Init(path string) {
 	ensureStarted(path)
 }
 
+// InitContext is Init with the watcher tied to ctx: cancelling ctx stops it,
+// just as Stop does, so it can follow a service's root context. Either one
+// may be used; the last config loaded stays readable after the watcher stops.
+// Safe to call multiple times; only the first Init variant has an effect.
+func InitContext(ctx context.Context, path string) {
+	std().InitContext(ctx, path)
+}
+
+// MustInit is Init for services that treat missing secrets as fatal: it
+// panics, naming the path and the error, if the initial load fails. The
+// watcher is started before the panic, so a recovered caller still picks up
+// the file once it appears. Like Init, only the first call has an effect.
+func MustInit(path string) {
+	std().MustInit(path)
+}
+
+// InitMulti is Init for several files loaded in order, later files overriding
+// keys from earlier ones. Any of them may be missing at startup (a warning is
+// logged); each is watched and re-read on its own when it changes. The merge
+// is redone on every reload, so a key deleted from a later file falls back to
+// its value in an earlier one.
+// Safe to call multiple times; only the first Init or InitMulti has an effect.
+func InitMulti(paths ...string) {
+	std().InitMulti(paths...)
+}
+
+// InitNamespaced is InitMulti for files whose keys are namespaced: files maps
+// each path to a prefix, and a key K from that file is stored as PREFIX_K,
+// so HOST in db.env mapped to "DB" becomes DB_HOST. An empty prefix keeps
+// the file's keys as they are. Files are merged in lexical path order, so if
+// two namespaces produce the same key, the file whose path sorts last wins.
+// Safe to call multiple times; only the first Init variant has an effect.
+func InitNamespaced(files map[string]string) {
+	std().InitNamespaced(files)
+}
+
+// Reload re-reads every file now, without waiting for a file event or the
+// debounce, and stores the result like a watcher-triggered reload would.
+// OnReload callbacks usually run before it returns; if callbacks of an
+// earlier reload are still running, as when Reload is called from one,
+// they are queued behind those and Reload returns without waiting. A read,
+// parse or validation error is returned and the current config is kept.
+func Reload() error {
+	ensureStarted("")
+	return std().Reload()
+}
+
+// Default returns the Env behind the package-level API, starting it like
+// Getenv would. Use it where an *Env is expected, e.g. NewGroup or BindAtomicTo.
+func Default() *Env {
+	ensureStarted("")
+	return std()
+}
+
 // Stop stops the background watcher (useful for tests/shutdown).
 func Stop() {
-	stopOnce.Do(func() {
-		if cancelFunc != nil {
-			cancelFunc()
-		}
-	})
+	std().Stop()
+}
+
+// Reset stops the default watcher and replaces the default instance with a
+// fresh one: the config is cleared, every With* setting (fallback to the
+// process env included) returns to its default, registered callbacks are
+// dropped, and the next Init/Getenv starts over, possibly on a different
+// file. Unlike Stop, it leaves the package ready to be initialized again.
+//
+// Reset is intended for tests and shutdown-then-restart scenarios; it is
+// only safe while nothing else is using the package-level API.
+func Reset() {
+	stdEnv.Swap(newEnv()).Stop()
 }
 
 // WithFallbackToProcessEnv controls whether os.Getenv is consulted
 // when a key is missing from the file. Default: true.
 func WithFallbackToProcessEnv(enabled bool) {
-	optFallbackToProcessEnv.Store(enabled)
+	OptFallbackToProcessEnv(enabled)(std())
 }
 
 // WithLogger lets you override the logger (printf-style). Call before Init/Getenv.
 func WithLogger(fn func(format string, v ...any)) {
-	if fn != nil {
-		optLogger = fn
-	}
+	OptLogger(fn)(std())
+}
+
+// WithSlogLogger sends log output to l as structured records. Whichever of
+// WithLogger and WithSlogLogger is called last wins. Call before Init/Getenv.
+func WithSlogLogger(l *slog.Logger) {
+	OptSlogLogger(l)(std())
 }
 
 // WithDefaultPath lets you override the implicit file path used by lazy init.
 // Call before Init/Getenv.
 func WithDefaultPath(path string) {
-	if path != "" {
-		defaultPath = path
-	}
+	OptDefaultPath(path)(std())
+}
+
+// WithDebounce sets the delay between the last file event and the reload.
+// Values <= 0 are ignored and the default (800ms) is kept. Call before Init/Getenv.
+func WithDebounce(d time.Duration) {
+	OptDebounce(d)(std())
+}
+
+// WithWarnOnDefault logs a one-time warning per key when Getenv returns a
+// caller-supplied default for a key found nowhere. Call before Init/Getenv.
+func WithWarnOnDefault(enabled bool) {
+	OptWarnOnDefault(enabled)(std())
+}
+
+// WithValidator adds a validator run against every candidate config before
+// it's stored; a rejected reload keeps the previous config. Call before Init/Getenv.
+func WithValidator(fn func(map[string]string) error) {
+	OptValidator(fn)(std())
+}
+
+// WithTypedValidator adds a validator run against every candidate config
+// before it's stored; a failing config is rejected. Call before Init/Getenv.
+func WithTypedValidator(fn func(*View) error) {
+	OptTypedValidator(fn)(std())
+}
+
+// WithMaxConfigBytes rejects any config whose keys and values add up to more
+// than n bytes. 0 means no limit. Call before Init/Getenv.
+func WithMaxConfigBytes(n int) {
+	OptMaxConfigBytes(n)(std())
+}
+
+// WithEnvironmentResolver sets a function naming the active environment; its
+// "<path>.<environment>" overlay is loaded on top of each configured path.
+// Call before Init/Getenv.
+func WithEnvironmentResolver(fn func() string) {
+	OptEnvironmentResolver(fn)(std())
+}
+
+// WithExpvar publishes reload statistics for the default config under name
+// in expvar (visible at /debug/vars). Call before Init/Getenv.
+func WithExpvar(name string) {
+	OptExpvar(name)(std())
+}
+
+// WithHistorySize keeps the last n reloads of the default config for History.
+// Call before Init/Getenv.
+func WithHistorySize(n int) {
+	OptHistorySize(n)(std())
+}
+
+// WithInterpolation enables ${NAME}-style references in values of the
+// default config. Call before Init/Getenv.
+func WithInterpolation(enabled bool) {
+	OptInterpolation(enabled)(std())
+}
+
+// WithValueTransform normalizes the value of key in the default config on
+// every load. Call before Init/Getenv.
+func WithValueTransform(key string, fn func(string) string) {
+	OptValueTransform(key, fn)(std())
+}
+
+// WithOnSourceMissing sets a callback for reloads that find the file gone,
+// as opposed to unparsable. Call before Init/Getenv.
+func WithOnSourceMissing(fn func()) {
+	OptOnSourceMissing(fn)(std())
+}
+
+// WithPollingInterval makes the default config poll its files every d
+// instead of using fsnotify; 0 restores fsnotify. Call before Init/Getenv.
+func WithPollingInterval(d time.Duration) {
+	OptPollingInterval(d)(std())
+}
+
+// WithSensitiveKeys marks keys of the default config whose values must never
+// be shown, see Redact. Call before Init/Getenv.
+func WithSensitiveKeys(keys ...string) {
+	OptSensitiveKeys(keys...)(std())
+}
+
+// WithKeyPrefix makes the default config load only the keys starting with
+// prefix, stored without it. Call before Init/Getenv.
+func WithKeyPrefix(prefix string) {
+	OptKeyPrefix(prefix)(std())
+}
+
+// WithInitRetry retries a failed initial load of the default config up to
+// maxAttempts more times, delay apart. Call before Init/Getenv.
+func WithInitRetry(maxAttempts int, delay time.Duration) {
+	OptInitRetry(maxAttempts, delay)(std())
+}
+
+// WithCaseInsensitiveKeys makes keys of the default config case-insensitive.
+// Call before Init/Getenv.
+func WithCaseInsensitiveKeys(enabled bool) {
+	OptCaseInsensitiveKeys(enabled)(std())
+}
+
+// WithSyncToProcessEnv mirrors the default config into the process
+// environment on every load. Call before Init/Getenv.
+func WithSyncToProcessEnv(enabled bool) {
+	OptSyncToProcessEnv(enabled)(std())
+}
+
+// WithMaskedLogging masks config values that reach the default config's log
+// output through errors or panics. Call before Init/Getenv.
+func WithMaskedLogging(enabled bool) {
+	OptMaskedLogging(enabled)(std())
+}
+
+// WithEnvPriority sets whether the file (PriorityFile, the default) or the
+// process environment (PriorityEnv) wins for the default config.
+// Call before Init/Getenv.
+func WithEnvPriority(p Priority) {
+	OptEnvPriority(p)(std())
+}
+
+// WithStrictParsing makes malformed lines fail the load of the default
+// config rather than being skipped. Call before Init/Getenv.
+func WithStrictParsing(enabled bool) {
+	OptStrictParsing(enabled)(std())
+}
+
+// WithMetrics reports the default config's reload events to m.
+// Call before Init/Getenv.
+func WithMetrics(m Metrics) {
+	OptMetrics(m)(std())
+}
+
+// WithLocalOverride adds an optional override file whose keys win over the
+// default config's files. Call before Init/Getenv.
+func WithLocalOverride(path string) {
+	OptLocalOverride(path)(std())
+}
+
+// WithEscapeSequences decodes escape sequences in unquoted values of the
+// default config too. Call before Init/Getenv.
+func WithEscapeSequences(enabled bool) {
+	OptEscapeSequences(enabled)(std())
+}
+
+// WithContext ties the default config's watcher to ctx, see InitContext.
+// Call before Init/Getenv.
+func WithContext(ctx context.Context) {
+	OptContext(ctx)(std())
+}
+
+// WithMaxFileSize sets the largest file the default config reads (default
+// 4 MiB; 0 disables the check). Call before Init/Getenv.
+func WithMaxFileSize(n int64) {
+	OptMaxFileSize(n)(std())
+}
+
+// WithRequiredFileMode refuses files of the default config whose permissions
+// exceed mode, e.g. 0o600. Call before Init/Getenv.
+func WithRequiredFileMode(mode os.FileMode) {
+	OptRequiredFileMode(mode)(std())
+}
+
+// WithAtomicSymlinkMode reloads the default config right after an atomic
+// swap of its file instead of after the debounce. Call before Init/Getenv.
+func WithAtomicSymlinkMode(enabled bool) {
+	OptAtomicSymlinkMode(enabled)(std())
+}
+
+// WithReloadErrorPolicy sets what a failed reload of the default config does
+// besides keeping the last good config. Call before Init/Getenv.
+func WithReloadErrorPolicy(p ReloadErrorPolicy) {
+	OptReloadErrorPolicy(p)(std())
+}
+
+// WithReloadErrorHandler sets the handler called for failed reloads of the
+// default config under PolicyKeepLastAndNotify. Call before Init/Getenv.
+func WithReloadErrorHandler(fn func(err error)) {
+	OptReloadErrorHandler(fn)(std())
+}
+
+// WithDecryptor sets fn to decrypt every file of the default config before
+// it is parsed. Call before Init/Getenv.
+func WithDecryptor(fn func(raw []byte) ([]byte, error)) {
+	OptDecryptor(fn)(std())
+}
+
+// WithDefaults registers fallback values for the default config, as if by
+// SetDefault for each entry. Call before Init/Getenv.
+func WithDefaults(defaults map[string]string) {
+	OptDefaults(defaults)(std())
+}
+
+// WithFormat sets how the default config's files are parsed, overriding the
+// detection by file name. Call before Init/Getenv.
+func WithFormat(f Format) {
+	OptFormat(f)(std())
+}
+
+// WithMissingHandler replaces the panic raised by MustGetenv for a missing
+// key. Call before Init/Getenv.
+func WithMissingHandler(fn func(key string)) {
+	OptMissingHandler(fn)(std())
 }
 
 // --------- Internals ----------
 
 func ensureStarted(path string) {
-	initOnce.Do(func() {
-		if path == "" {
-			if p := os.Getenv("SECRETS_FILE"); p != "" {
-				path = p
-			} else {
-				path = defaultPath
-			}
-		}
-		// initial load
-		if c, err := loadEnvFile(path); err == nil {
-			cfg.Store(c)
-		} else {
-			optLogger("hotenv: initial load failed: %v (continuing with empty config)", err)
-			cfg.Store(config{m: map[string]string{}})
-		}
-		// start watcher
-		ctx, cancel := context.WithCancel(context.Background())
-		cancelFunc = cancel
-		go watchAndReload(ctx, path, defaultDebounce)
-	})
-}
-
-func get(key string) string {
-	// 1) file-based
-	if cur, ok := cfg.Load().(config); ok {
-		if v := cur.m[key]; v != "" {
-			return v
-		}
+	std().Init(path)
+}
+
+// loadSource loads path as a directory of files (see loadEnvDir) if it is a
+// directory, and as a single .env, JSON or YAML file (see OptFormat) otherwise.
+func (e *Env) loadSource(path string) (config, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return e.loadEnvDir(path)
 	}
-	// 2) optional process env fallback
-	if optFallbackToProcessEnv.Load() {
-		if v := os.Getenv(key); v != "" {
-			return v
-		}
+	if f := e.formatFor(path); f == FormatJSON || f == FormatYAML {
+		return e.loadStructuredFile(path, f)
 	}
-	return ""
+	return e.loadEnvFile(path)
 }
 
-func watchAndReload(ctx context.Context, filePath string, debounce time.Duration) {
-	dir := filepath.Dir(filePath)
+// loadEnvDir loads a Kubernetes-style secret mount: every regular file in dir
+// becomes one key (file name = key, trimmed contents = value). Dotfiles are
+// skipped, which also covers the ..data and ..<timestamp> entries K8s uses for
+// atomic updates; the per-key symlinks pointing into them are followed.
+func (e *Env) loadEnvDir(dir string) (config, error) {
+	out := make(map[string]string)
 
-	w, err := fsnotify.NewWatcher()
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		optLogger("hotenv: watcher init failed: %v", err)
-		return
-	}
-	defer w.Close()
-
-	if err := w.Add(dir); err != nil {
-		optLogger("hotenv: watch add failed: %v", err)
-		return
+		return config{m: out}, err
 	}
-
-	var timerMu sync.Mutex
-	var timer *time.Timer
-	trigger := func() {
-		timerMu.Lock()
-		defer timerMu.Unlock()
-		if timer != nil {
-			_ = timer.Stop()
+	for _, ent := range entries {
+		name := ent.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
 		}
-		timer = time.AfterFunc(debounce, func() {
-			if c, err := loadEnvFile(filePath); err == nil {
-				cfg.Store(c)
-				optLogger("hotenv: reloaded (%d keys)", len(c.m))
-			} else {
-				optLogger("hotenv: reload failed: %v", err)
-			}
-		})
-	}
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case ev, ok := <-w.Events:
-			if !ok {
-				return
-			}
-			// Any change in dir (K8s does atomic swaps) -> reload
-			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename|fsnotify.Chmod) != 0 {
-				trigger()
-			}
-		case err := <-w.Errors:
-			optLogger("hotenv: watch error: %v", err)
+		p := filepath.Join(dir, name)
+		fi, err := os.Stat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		b, err := e.readFile(p)
+		if err != nil {
+			return config{m: out}, err
 		}
+		out[e.foldKey(name)] = strings.TrimSpace(string(b))
 	}
+	return config{m: out}, nil
 }
 
 // loadEnvFile supports:
-// - KEY=VALUE (one line)
-// - blank lines and # comments
+// - KEY=VALUE (one line), optionally prefixed with "export " as in shell scripts
+// - blank lines and # comments, including trailing " # ..." after unquoted values
 // - multi-line values quoted with '...' or "..." (closing quote can be on a later line)
-func loadEnvFile(path string) (config, error) {
+// - unquoted values continued by a trailing backslash (an escaped "\\" doesn't continue and reads as "\")
+func (e *Env) loadEnvFile(path string) (config, error) {
 	out := make(map[string]string)
 
-	f, err := os.Open(path)
+	b, err := e.readFile(path)
 	if err != nil {
 		return config{m: out}, err
 	}
-	defer f.Close()
 
-	sc := bufio.NewScanner(f)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	// no line can be longer than the file, so a long certificate or JSON
+	// blob on one line never trips bufio.ErrTooLong
+	sc.Buffer(nil, max(len(b)+1, bufio.MaxScanTokenSize))
 	var key, value string
-	var inMultiline bool
+	var inMultiline, continued bool
 	var quote rune
+	var lineNo, startLine int
+	var malformed []string // strict mode only; line numbers and keys, never values
+
+	storeUnquoted := func() {
+		if e.opts.escapeSequences {
+			value = unquote(value, '"')
+		}
+		out[key] = value
+		key, value = "", ""
+	}
 
 	for sc.Scan() {
 		line := sc.Text()
+		lineNo++
+		if continued {
+			// next line of an unquoted value ending in a backslash; the
+			// backslash and the line break are dropped, indentation is kept
+			var piece string
+			piece, continued = cutContinuation(stripInlineComment(strings.TrimRight(line, " \t\r")))
+			value += piece
+			if !continued {
+				storeUnquoted()
+			}
+			continue
+		}
 		if !inMultiline {
 			trim := strings.TrimSpace(line)
 			if trim == "" || strings.HasPrefix(trim, "#") {
 				continue
 			}
+			trim = stripExport(trim)
 			kv := strings.SplitN(trim, "=", 2)
 			if len(kv) != 2 {
+				if e.opts.strictParsing {
+					// the line may well be a stray value, so only its number is reported
+					malformed = append(malformed, fmt.Sprintf("line %d: missing \"=\"", lineNo))
+				}
 				continue
 			}
-			key = strings.TrimSpace(kv[0])
+			key = e.foldKey(strings.TrimSpace(kv[0]))
 			value = strings.TrimSpace(kv[1])
+			if key == "" && e.opts.strictParsing {
+				malformed = append(malformed, fmt.Sprintf("line %d: empty key", lineNo))
+				continue
+			}
 
-			// quoted single-line or start of multi-line
-			if len(value) >= 2 {
+			// quoted single-line or start of multi-line; what's between the
+			// quotes is kept verbatim, whitespace included
+			if value != "" && (value[0] == '\'' || value[0] == '"') {
 				start := rune(value[0])
-				end := rune(value[len(value)-1])
-				if (start == '\'' || start == '"') && end == start {
-					out[key] = strings.TrimSuffix(strings.TrimPrefix(value, string(start)), string(start))
-					key, value = "", ""
-					continue
+				// trim has lost trailing whitespace, so take the body from line
+				_, raw, _ := strings.Cut(line, "=")
+				body := strings.TrimLeft(raw, " \t")[1:]
+				i, clean := closingQuote(body, value[0])
+				if !clean && i >= 0 && e.opts.strictParsing {
+					// A="x" y: a quote, but not one that ends the value
+					malformed = append(malformed, fmt.Sprintf("line %d: text after closing quote for %q", lineNo, key))
+					clean = true
 				}
-				if (start == '\'' || start == '"') && end != start {
-					inMultiline = true
-					quote = start
-					value = strings.TrimPrefix(value, string(start)) + "\n"
+				if clean {
+					out[key] = unquote(body[:i], start)
+					key, value = "", ""
 					continue
 				}
+				inMultiline = true
+				startLine = lineNo
+				quote = start
+				value = body + "\n"
+				continue
+			}
+			// unquoted, possibly continued on the next line
+			if value, continued = cutContinuation(stripInlineComment(value)); !continued {
+				storeUnquoted()
 			}
-			// unquoted single-line
-			out[key] = value
-			key, value = "", ""
 		} else {
-			// collecting multi-line until closing quote
-			if strings.HasSuffix(line, string(quote)) {
-				value += strings.TrimSuffix(line, string(quote))
-				out[key] = value
+			// collecting multi-line until closing quote, which may be
+			// followed by whitespace
+			if end := strings.TrimRight(line, " \t"); closesQuote(end, byte(quote)) {
+				value += strings.TrimSuffix(end, string(quote))
+				out[key] = unquote(value, quote)
 				inMultiline = false
 				key, value = "", ""
 			} else {
@@ -242,5 +586,168 @@ func loadEnvFile(path string) (config, error) {
 	if err := sc.Err(); err != nil {
 		return config{m: out}, err
 	}
+	if continued {
+		// a backslash on the last line has nothing to join
+		storeUnquoted()
+	}
+	if inMultiline && e.opts.strictParsing {
+		malformed = append(malformed, fmt.Sprintf("line %d: unterminated quoted value for %q", startLine, key))
+	}
+	// an entry without a key ("=x", "export =x") is parsed, so that a quoted
+	// value spanning lines is consumed, but not kept
+	delete(out, "")
+	if len(malformed) > 0 {
+		return config{m: out}, fmt.Errorf("%s: malformed lines: %s", path, strings.Join(malformed, "; "))
+	}
 	return config{m: out}, nil
 }
+
+// readFile reads a source file after checking it (see checkFile) and
+// decrypts it with OptDecryptor if one is set.
+func (e *Env) readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkFile(path, fi); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if fn := e.opts.decryptor; fn != nil {
+		if b, err = fn(b); err != nil {
+			return nil, fmt.Errorf("%s: decrypt: %w", path, err)
+		}
+	}
+	return b, nil
+}
+
+// checkFile rejects a file before it is read if it is larger than
+// OptMaxFileSize or has permissions outside OptRequiredFileMode.
+func (e *Env) checkFile(path string, fi os.FileInfo) error {
+	if limit := e.opts.maxFileSize; limit > 0 && fi.Size() > limit {
+		return fmt.Errorf("%s: file is %d bytes, over the %d byte limit", path, fi.Size(), limit)
+	}
+	if mask := e.opts.requiredFileMode; mask != 0 && fi.Mode().Perm()&^mask != 0 {
+		return fmt.Errorf("%s: file mode %04o allows more than %04o", path, fi.Mode().Perm(), mask)
+	}
+	return nil
+}
+
+// stripExport removes a leading shell "export" keyword followed by
+// whitespace. Keys that merely start with "export" are left alone.
+func stripExport(line string) string {
+	rest, ok := strings.CutPrefix(line, "export")
+	if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return line
+	}
+	return strings.TrimSpace(rest)
+}
+
+// stripInlineComment cuts an unquoted value at the first "#" preceded by
+// whitespace, so "8080   # port" becomes "8080" while "pass#word" is kept.
+func stripInlineComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// cutContinuation reports whether the unquoted piece s ends in a backslash
+// that continues the value on the next line, and returns s without it. An
+// even run of trailing backslashes is escaped and doesn't continue; its last
+// pair is collapsed to one backslash, so KEY=foo\\ reads as foo\.
+func cutContinuation(s string) (string, bool) {
+	n := len(s) - len(strings.TrimRight(s, `\`))
+	if n%2 == 0 {
+		if n > 0 {
+			s = s[:len(s)-1]
+		}
+		return s, false
+	}
+	return s[:len(s)-1], true
+}
+
+// closesQuote reports whether s ends with the quote q. Inside double quotes a
+// quote preceded by an odd number of backslashes is escaped and doesn't count.
+func closesQuote(s string, q byte) bool {
+	if !strings.HasSuffix(s, string(q)) {
+		return false
+	}
+	if q != '"' {
+		return true
+	}
+	n := 0
+	for i := len(s) - 2; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 0
+}
+
+// closingQuote returns the index of the quote q that closes a value opened
+// on this line, s being the text after the opening quote. Quotes inside the
+// value are allowed, as in 'it's' or "{"a":1}", so the closer is the first
+// unescaped q followed only by whitespace or a comment (clean == true). If
+// there is none the value continues on the next lines, and i is the last
+// unescaped q, or -1 if s has none at all.
+func closingQuote(s string, q byte) (i int, clean bool) {
+	last := -1
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && q == '"':
+			i++
+		case s[i] == q:
+			if isTrailer(s[i+1:]) {
+				return i, true
+			}
+			last = i
+		}
+	}
+	return last, false
+}
+
+// isTrailer reports whether s, the rest of a line after a closing quote, is
+// empty or only whitespace and a comment.
+func isTrailer(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#'
+}
+
+// unquote interprets \n, \t, \r, \\ and \" in a double-quoted value. Other
+// backslashes are kept as written; single-quoted values are always literal.
+func unquote(s string, quote rune) string {
+	if quote != '"' || !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '"':
+			b.WriteByte(s[i+1])
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package hotenv
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes content to name in a fresh temporary directory and
+// returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// parse loads content as a .env file with opts applied.
+func parse(t *testing.T, content string, opts ...Option) map[string]string {
+	t.Helper()
+	c, err := newEnv(opts...).loadEnvFile(writeFile(t, ".env", content))
+	if err != nil {
+		t.Fatalf("loadEnvFile: %v", err)
+	}
+	return c.m
+}
+
+func TestParseQuotedWhitespace(t *testing.T) {
+	got := parse(t, "A=\"  abc  \"\nB=   plain   \nC=  \"x\"  \nD='  y  ' # padded\n")
+	want := map[string]string{"A": "  abc  ", "B": "plain", "C": "x", "D": "  y  "}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseQuotesInsideValue(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          map[string]string
+	}{
+		{"single quote inside single quotes", "A='it's'\nNEXT=ok\n", map[string]string{"A": "it's", "NEXT": "ok"}},
+		{"double quotes inside double quotes", "JSON=\"{\"a\":1}\"\nNEXT=ok\n", map[string]string{"JSON": `{"a":1}`, "NEXT": "ok"}},
+		{"comment after closing quote", "A=\"x\" # say \"hi\"\nNEXT=ok\n", map[string]string{"A": "x", "NEXT": "ok"}},
+		{"escaped quote", "A=\"say \\\"hi\\\"\"\nNEXT=ok\n", map[string]string{"A": `say "hi"`, "NEXT": "ok"}},
+		{"multi-line", "A=\"line1\nline2\"\nNEXT=ok\n", map[string]string{"A": "line1\nline2", "NEXT": "ok"}},
+		{"multi-line with a quote on the first line", "JSON=\"{\"a\":1,\n\"b\":2}\"\nNEXT=ok\n", map[string]string{"JSON": "{\"a\":1,\n\"b\":2}", "NEXT": "ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parse(t, tt.content); !maps.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStrictTextAfterQuote(t *testing.T) {
+	env := newEnv(OptStrictParsing(true))
+	if _, err := env.loadEnvFile(writeFile(t, ".env", "A=\"x\" y\n")); err == nil {
+		t.Error("want an error for text after the closing quote")
+	}
+}
+
+func TestParseExport(t *testing.T) {
+	got := parse(t, "export A=plain\nexport B=\"double quoted\"\nexport\tC='single quoted'\n")
+	want := map[string]string{"A": "plain", "B": "double quoted", "C": "single quoted"}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseInlineComments(t *testing.T) {
+	tests := []struct{ content, want string }{
+		{"KEY=val # c\n", "val"},
+		{"KEY=val#nospace\n", "val#nospace"},
+		{"KEY=\"a # b\"\n", "a # b"},
+		{"KEY='a # b' # c\n", "a # b"},
+	}
+	for _, tt := range tests {
+		if got := parse(t, tt.content)["KEY"]; got != tt.want {
+			t.Errorf("%q: KEY = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParseExportEdgeCases(t *testing.T) {
+	got := parse(t, "exportB=2\nexport\nexport =x\nM=\"first\nexport X=1\nlast\"\n")
+	want := map[string]string{"exportB": "2", "M": "first\nexport X=1\nlast"}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseCaseInsensitiveDuplicates(t *testing.T) {
+	got := parse(t, "db_host=first\nDB_HOST=second\nDb_Host=last\n", OptCaseInsensitiveKeys(true))
+	want := map[string]string{"DB_HOST": "last"}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseContinuation(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+		opts                []Option
+	}{
+		{"continued", "KEY=a \\\nb\n", "a b", nil},
+		{"escaped backslash", "KEY=foo\\\\\n", `foo\`, nil},
+		{"escaped backslash with escape sequences", "KEY=foo\\\\\n", `foo\`, []Option{OptEscapeSequences(true)}},
+		{"escaped backslash ends continuation", "KEY=a \\\nb\\\\\nNEXT=ok\n", `a b\`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parse(t, tt.content, tt.opts...)["KEY"]; got != tt.want {
+				t.Errorf("KEY = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEscapeSequences(t *testing.T) {
+	got := parse(t, "U=a\\nb\nD=\"say \\\"hi\\\"\n\\tindented\"\nS='a\\nb\n\\t'\n", OptEscapeSequences(true))
+	want := map[string]string{"U": "a\nb", "D": "say \"hi\"\n\tindented", "S": "a\\nb\n\\t"}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := parse(t, "U=a\\nb\n")["U"]; got != `a\nb` {
+		t.Errorf("without OptEscapeSequences U = %q, want it literal", got)
+	}
+}
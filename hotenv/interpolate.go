@@ -0,0 +1,152 @@
+package hotenv
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// interpolate expands ${NAME}, $NAME and ${NAME:-default} references in the
+// values of c, returning a new config. References resolve against other keys
+// of c first (in any order, so forward references work) and then, when
+// fallback is enabled, the process environment. "$$" is a literal "$".
+// Unresolved references without a default are left as written and logged,
+// so a typo stays visible in the value. A key referring to itself, as in
+// PATH=${PATH}:/extra, resolves against the process environment like in a
+// shell; any other reference cycle is an error.
+func (e *Env) interpolate(c config) (config, error) {
+	x := &expander{
+		e:         e,
+		raw:       c.m,
+		done:      make(map[string]string, len(c.m)),
+		resolving: make(map[string]bool),
+		missing:   make(map[string]bool),
+	}
+	out := config{m: make(map[string]string, len(c.m))}
+	for k := range c.m {
+		v, err := x.resolve(k)
+		if err != nil {
+			return c, fmt.Errorf("interpolation: %w", err)
+		}
+		out.m[k] = v
+	}
+	if len(x.missing) > 0 {
+		e.logWarn("unresolved references left as-is", slog.Any("names", slices.Sorted(maps.Keys(x.missing))))
+	}
+	return out, nil
+}
+
+type expander struct {
+	e         *Env
+	raw       map[string]string
+	done      map[string]string // fully expanded values
+	resolving map[string]bool   // keys on the current resolution path
+	path      []string
+	missing   map[string]bool // referenced names that resolved nowhere
+}
+
+func (x *expander) resolve(key string) (string, error) {
+	if v, ok := x.done[key]; ok {
+		return v, nil
+	}
+	if x.resolving[key] {
+		return "", fmt.Errorf("reference cycle %s -> %s", strings.Join(x.path, " -> "), key)
+	}
+	x.resolving[key] = true
+	x.path = append(x.path, key)
+	v, err := x.expand(x.raw[key])
+	x.path = x.path[:len(x.path)-1]
+	delete(x.resolving, key)
+	if err != nil {
+		return "", err
+	}
+	x.done[key] = v
+	return v, nil
+}
+
+// lookup resolves a referenced name. A self-reference skips the config.
+func (x *expander) lookup(name string) (string, bool, error) {
+	name = x.e.foldKey(name)
+	self := len(x.path) > 0 && x.path[len(x.path)-1] == name
+	if _, ok := x.raw[name]; ok && !self {
+		v, err := x.resolve(name)
+		return v, true, err
+	}
+	if x.e.fallbackToProcessEnv.Load() {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (x *expander) expand(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				// unterminated: keep literally
+				b.WriteString(s[i:])
+				return b.String(), nil
+			}
+			ref := s[i+2 : i+2+end]
+			name, def, hasDef := strings.Cut(ref, ":-")
+			v, ok, err := x.lookup(name)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case hasDef && (!ok || v == ""):
+				if v, err = x.expand(def); err != nil {
+					return "", err
+				}
+			case !ok:
+				x.missing[name] = true
+				v = s[i : i+3+end]
+			}
+			b.WriteString(v)
+			i += 2 + end
+		case isNameStart(next):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			v, ok, err := x.lookup(s[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				x.missing[s[i+1:j]] = true
+				v = s[i:j]
+			}
+			b.WriteString(v)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,24 @@
+package hotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolateSelfReference(t *testing.T) {
+	t.Setenv("HOTENV_TEST_PATH", "/usr/bin")
+	c, err := newEnv(OptInterpolation(true)).interpolate(config{m: map[string]string{
+		"HOTENV_TEST_PATH": "${HOTENV_TEST_PATH}:/extra",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.m["HOTENV_TEST_PATH"]; got != "/usr/bin:/extra" {
+		t.Errorf("self-reference = %q, want it resolved against the process environment", got)
+	}
+
+	_, err = newEnv(OptInterpolation(true)).interpolate(config{m: map[string]string{"A": "${B}", "B": "$A"}})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("A -> B -> A: %v, want a reference cycle error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package hotenv
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// log emits msg at level. With a slog logger configured (OptSlogLogger) the
+// attrs are passed through as structured fields; otherwise the line is
+// rendered for the printf-style logger as "hotenv: msg key=value ...: error".
+func (e *Env) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if e.opts.maskedLogging {
+		attrs = e.scrubAttrs(attrs)
+	}
+	if l := e.opts.slogger; l != nil {
+		l.LogAttrs(context.Background(), level, "hotenv: "+msg, attrs...)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("hotenv: ")
+	b.WriteString(msg)
+	var errText string
+	for _, a := range attrs {
+		if a.Key == "error" {
+			errText = a.Value.String()
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, a.Value)
+	}
+	if errText != "" {
+		b.WriteString(": ")
+		b.WriteString(errText)
+	}
+	e.opts.logger("%s", b.String())
+}
+
+func (e *Env) logInfo(msg string, attrs ...slog.Attr)  { e.log(slog.LevelInfo, msg, attrs...) }
+func (e *Env) logWarn(msg string, attrs ...slog.Attr)  { e.log(slog.LevelWarn, msg, attrs...) }
+func (e *Env) logError(msg string, attrs ...slog.Attr) { e.log(slog.LevelError, msg, attrs...) }
+
+// scrubAttrs returns attrs with any value of the current config masked in
+// string and free-form attributes, e.g. an error or panic built by a callback.
+func (e *Env) scrubAttrs(attrs []slog.Attr) []slog.Attr {
+	c := e.snapshot()
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindString, slog.KindAny:
+			a = slog.String(a.Key, e.scrub(a.Value.String(), c))
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// pathAttr names the watched file(s).
+func (e *Env) pathAttr() slog.Attr {
+	return slog.String("path", strings.Join(e.paths, ","))
+}
+
+func errAttr(err error) slog.Attr {
+	return slog.String("error", err.Error())
+}
@@ -0,0 +1,509 @@
+package hotenv
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Option configures an *Env created with New.
+type Option func(*Env)
+
+type options struct {
+	defaultPath string
+	debounce    time.Duration
+	logger      func(format string, v ...any)
+	slogger     *slog.Logger
+
+	warnOnDefault   bool
+	validators      []func(map[string]string) error
+	typedValidators []func(*View) error
+	maxConfigBytes  int
+
+	environmentResolver func() string
+	expvarName          string
+	historySize         int
+	interpolate         bool
+	transforms          map[string][]func(string) string
+	onSourceMissing     func()
+	missingHandler      func(key string)
+	pollInterval        time.Duration
+	sensitiveKeys       map[string]bool
+	keyPrefix           string
+	initRetries         int
+	initRetryDelay      time.Duration
+	caseInsensitive     bool
+	syncToProcessEnv    bool
+	maskedLogging       bool
+	priority            Priority
+	strictParsing       bool
+	metrics             Metrics
+	localOverride       string
+	escapeSequences     bool
+	ctx                 context.Context
+	format              Format
+	maxFileSize         int64
+	requiredFileMode    os.FileMode
+	atomicSymlink       bool
+	reloadErrorPolicy   ReloadErrorPolicy
+	reloadErrorHandler  func(error)
+	decryptor           func(raw []byte) ([]byte, error)
+}
+
+func defaultOptions() options {
+	return options{
+		defaultPath: "/app/secrets/.env",
+		debounce:    800 * time.Millisecond,
+		logger:      log.Printf,
+		maxFileSize: defaultMaxFileSize,
+	}
+}
+
+// OptFallbackToProcessEnv controls whether os.Getenv is consulted
+// when a key is missing from the file. Default: true.
+func OptFallbackToProcessEnv(enabled bool) Option {
+	return func(e *Env) {
+		e.fallbackToProcessEnv.Store(enabled)
+	}
+}
+
+// OptLogger overrides the logger (printf-style). A nil fn is ignored.
+// It replaces a logger set by OptSlogLogger; the last one set wins.
+func OptLogger(fn func(format string, v ...any)) Option {
+	return func(e *Env) {
+		if fn != nil {
+			e.opts.logger = fn
+			e.opts.slogger = nil
+		}
+	}
+}
+
+// OptSlogLogger sends log output to l as structured records, with levels
+// (reloads at Info, failures at Error) and attributes such as "path" and
+// "keys". It replaces a logger set by OptLogger; the last one set wins.
+// A nil l is ignored.
+func OptSlogLogger(l *slog.Logger) Option {
+	return func(e *Env) {
+		if l != nil {
+			e.opts.slogger = l
+		}
+	}
+}
+
+// OptDefaultPath overrides the file path used when New or Init is given "".
+func OptDefaultPath(path string) Option {
+	return func(e *Env) {
+		if path != "" {
+			e.opts.defaultPath = path
+		}
+	}
+}
+
+// OptDebounce sets how long the watcher waits after the last file event
+// before reloading. Default: 800ms. Values <= 0 are ignored so a misconfigured
+// interval can't turn the watcher into a busy reload loop.
+func OptDebounce(d time.Duration) Option {
+	return func(e *Env) {
+		if d > 0 {
+			e.opts.debounce = d
+		}
+	}
+}
+
+// OptWarnOnDefault logs a one-time warning per key whenever Getenv falls back
+// to a caller-supplied default because the key is in neither the file nor
+// the process environment. Useful for catching misspelled key names.
+func OptWarnOnDefault(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.warnOnDefault = enabled
+	}
+}
+
+// OptValidator adds a validator that receives a copy of every candidate
+// config, the initial load included, before it is stored. If it returns an
+// error the config is rejected: a reload keeps the previous config, and a
+// failed initial load leaves the config empty, as a missing file would.
+func OptValidator(fn func(map[string]string) error) Option {
+	return func(e *Env) {
+		if fn != nil {
+			e.opts.validators = append(e.opts.validators, fn)
+		}
+	}
+}
+
+// OptTypedValidator adds a validator that sees the candidate config through
+// a View with typed getters, so cross-field rules (MIN < MAX, TLS_CERT
+// implies TLS_KEY) can be written against parsed values. All validators run
+// before a config is stored; if any fail, the config is rejected and every
+// failure is reported.
+func OptTypedValidator(fn func(*View) error) Option {
+	return func(e *Env) {
+		if fn != nil {
+			e.opts.typedValidators = append(e.opts.typedValidators, fn)
+		}
+	}
+}
+
+// OptMaxConfigBytes rejects a config whose keys and values add up to more than
+// n bytes (see EnvStats.ConfigBytes), keeping the last good config. This guards
+// against a runaway file, such as a log accidentally redirected into the
+// secrets file. 0 means no limit.
+func OptMaxConfigBytes(n int) Option {
+	return func(e *Env) {
+		if n >= 0 {
+			e.opts.maxConfigBytes = n
+		}
+	}
+}
+
+// defaultMaxFileSize is far above any real secrets file and far below what
+// it takes to exhaust a container's memory.
+const defaultMaxFileSize = 4 << 20
+
+// OptMaxFileSize rejects any file larger than n bytes before reading it,
+// keeping the last good config, so a mount that points at a huge file can't
+// exhaust memory. It applies to every file, including the ones in a
+// directory source. Default: 4 MiB; 0 means no limit.
+func OptMaxFileSize(n int64) Option {
+	return func(e *Env) {
+		if n >= 0 {
+			e.opts.maxFileSize = n
+		}
+	}
+}
+
+// OptRequiredFileMode refuses to load a file whose permission bits go
+// beyond mode, so OptRequiredFileMode(0o600) rejects a world- or
+// group-readable secrets file. The check runs on every load, before any
+// validator, and a failed reload keeps the last good config. It applies to
+// every file, including the ones in a directory source. 0, the default,
+// disables the check.
+func OptRequiredFileMode(mode os.FileMode) Option {
+	return func(e *Env) {
+		e.opts.requiredFileMode = mode.Perm()
+	}
+}
+
+// OptAtomicSymlinkMode tunes the watcher for sources replaced by an atomic
+// swap, as Kubernetes does for Secret and ConfigMap volumes: a Create,
+// Remove or Rename of a watched file or of the ..data symlink, such as the
+// rename of ..data_tmp onto ..data, triggers a reload after
+// a short settle delay (50ms) instead of the full debounce, with the
+// directory watch re-added first. Default: false.
+func OptAtomicSymlinkMode(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.atomicSymlink = enabled
+	}
+}
+
+// OptEnvironmentResolver sets a function that names the active environment
+// (e.g. "production", derived from POD_NAME or the hostname). When it returns
+// a non-empty name, every configured path gets an optional overlay,
+// "<path>.<environment>", loaded right after it. The resolver is consulted on
+// the initial load and on every reload, so a changed signal takes effect
+// without a restart.
+func OptEnvironmentResolver(fn func() string) Option {
+	return func(e *Env) {
+		e.opts.environmentResolver = fn
+	}
+}
+
+// OptExpvar publishes the Env's reload statistics (never any values) under
+// name in expvar, and so at /debug/vars when expvar's handler is mounted.
+// The variable is registered when the Env starts; if name is already taken,
+// a warning is logged instead.
+func OptExpvar(name string) Option {
+	return func(e *Env) {
+		e.opts.expvarName = name
+	}
+}
+
+// OptHistorySize keeps the last n reloads (timestamps, key counts and the
+// names of changed keys, never values) for History. 0, the default, keeps none.
+func OptHistorySize(n int) Option {
+	return func(e *Env) {
+		if n >= 0 {
+			e.opts.historySize = n
+		}
+	}
+}
+
+// OptInterpolation enables ${NAME}, $NAME and ${NAME:-default} expansion in
+// values, resolved against the other loaded keys and then the process
+// environment (when fallback is enabled). Write "$$" for a literal "$".
+// Default: false, so existing values containing "$" are left alone.
+func OptInterpolation(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.interpolate = enabled
+	}
+}
+
+// OptValueTransform normalizes the value of key on every load, before
+// validation and before it becomes visible, e.g. strings.ToLower for a region
+// code. Several transforms for the same key run in registration order.
+func OptValueTransform(key string, fn func(string) string) Option {
+	return func(e *Env) {
+		if fn == nil {
+			return
+		}
+		if e.opts.transforms == nil {
+			e.opts.transforms = make(map[string][]func(string) string)
+		}
+		e.opts.transforms[key] = append(e.opts.transforms[key], fn)
+	}
+}
+
+// ReloadErrorPolicy decides what happens when a reload fails; see
+// OptReloadErrorPolicy. Either way the last good config stays in place.
+type ReloadErrorPolicy int
+
+const (
+	// PolicyKeepLast keeps serving the last good config and only logs the
+	// error. This is the default.
+	PolicyKeepLast ReloadErrorPolicy = iota
+	// PolicyKeepLastAndNotify keeps the last good config, logs the error
+	// and passes it to the handler set with OptReloadErrorHandler.
+	PolicyKeepLastAndNotify
+)
+
+// OptReloadErrorPolicy sets what a failed reload does besides keeping the
+// last good config. A failed initial load is not a reload and is reported
+// by New or logged by Init instead.
+func OptReloadErrorPolicy(p ReloadErrorPolicy) Option {
+	return func(e *Env) {
+		e.opts.reloadErrorPolicy = p
+	}
+}
+
+// OptReloadErrorHandler sets fn to receive the error of every failed reload
+// (read, decrypt, parse or validation) under PolicyKeepLastAndNotify. The
+// error may quote values, e.g. from a validator; it is not scrubbed. fn
+// runs on the reload goroutine after the reload has released its lock, in
+// order with OnReload callbacks, so it may call Reload itself.
+func OptReloadErrorHandler(fn func(err error)) Option {
+	return func(e *Env) {
+		e.opts.reloadErrorHandler = fn
+	}
+}
+
+// OptDecryptor sets fn to turn the raw bytes of every file, including the
+// ones in a directory source, into the plaintext that is parsed, e.g. with
+// SOPS or age, so no plaintext secret has to sit on the volume. It runs on
+// every load and reload; if it fails, the last good config is kept and the
+// error is logged like any other reload failure. fn must not log the
+// plaintext.
+func OptDecryptor(fn func(raw []byte) ([]byte, error)) Option {
+	return func(e *Env) {
+		e.opts.decryptor = fn
+	}
+}
+
+// OptDefaults registers a fallback value for each key in defaults, used when
+// the key has no value in the file(s) or the process environment and the
+// caller passes no default of its own; see SetDefault and Getenv.
+func OptDefaults(defaults map[string]string) Option {
+	return func(e *Env) {
+		for k, v := range defaults {
+			e.SetDefault(k, v)
+		}
+	}
+}
+
+// OptOnSourceMissing sets fn to be called when a reload fails because a
+// watched file (or directory) no longer exists, e.g. after the secrets volume
+// was unmounted. Parse and validation failures don't call it. The last good
+// config is kept and the watcher keeps running, so when the file reappears a
+// normal reload follows. fn runs on the reload goroutine after the reload
+// has released its lock, like OptReloadErrorHandler.
+func OptOnSourceMissing(fn func()) Option {
+	return func(e *Env) {
+		e.opts.onSourceMissing = fn
+	}
+}
+
+// OptPollingInterval replaces the fsnotify watcher with a loop that checks
+// the size and modification time of every source each d, for platforms where
+// inotify is unavailable or unreliable (network filesystems, restricted
+// containers). Changes are picked up within d; the debounce doesn't apply.
+// 0, the default, uses fsnotify.
+func OptPollingInterval(d time.Duration) Option {
+	return func(e *Env) {
+		if d >= 0 {
+			e.opts.pollInterval = d
+		}
+	}
+}
+
+// OptSensitiveKeys marks keys whose values must never be shown, in addition
+// to the keys that look like secrets by name. Redact, ReloadDelta.Redacted and
+// DiffAgainstProcessEnv mask their values as "***". Getenv is not affected.
+func OptSensitiveKeys(keys ...string) Option {
+	return func(e *Env) {
+		if e.opts.sensitiveKeys == nil {
+			e.opts.sensitiveKeys = make(map[string]bool)
+		}
+		for _, k := range keys {
+			e.opts.sensitiveKeys[k] = true
+		}
+	}
+}
+
+// OptKeyPrefix loads only the keys starting with prefix and stores them
+// without it, for a file shared by several services: with "AUTHSVC_",
+// AUTHSVC_DB_URL is read as DB_URL and PAYMENTSVC_* keys are ignored. The
+// prefix is applied after interpolation, so references use the full names
+// as written in the file; transforms and validators see the stripped names.
+// "" (the default) loads every key.
+func OptKeyPrefix(prefix string) Option {
+	return func(e *Env) {
+		e.opts.keyPrefix = prefix
+	}
+}
+
+// OptInitRetry retries a failed initial load up to maxAttempts more times,
+// sleeping delay before each retry, for files written by a sidecar that may
+// not be done when the process starts. The retries happen before New or Init
+// returns. 0, the default, means no retries.
+func OptInitRetry(maxAttempts int, delay time.Duration) Option {
+	return func(e *Env) {
+		if maxAttempts >= 0 && delay >= 0 {
+			e.opts.initRetries = maxAttempts
+			e.opts.initRetryDelay = delay
+		}
+	}
+}
+
+// OptCaseInsensitiveKeys stores every key in upper case and upper-cases the
+// key of each lookup, the process-env fallback included, so Port= in the file
+// is found by Getenv("PORT"). Watch and Subscribe fold their key the same
+// way. Keys differing only by case collapse into one;
+// the last one in the file wins. Default: false, keys match exactly.
+func OptCaseInsensitiveKeys(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.caseInsensitive = enabled
+	}
+}
+
+// OptSyncToProcessEnv copies the config into the process environment with
+// os.Setenv after the initial load and every reload, for libraries that only
+// read os.Getenv. Only keys that were added or changed are set; keys removed
+// from the file are unset, even if they were in the environment before
+// hotenv started. Default: false, since it mutates global process state.
+func OptSyncToProcessEnv(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.syncToProcessEnv = enabled
+	}
+}
+
+// OptMaskedLogging hardens log output for regulated environments. hotenv's
+// own messages never include values; with masking enabled, any config value
+// (of 4 characters or more) that still shows up in a logged error or panic,
+// say a validator quoting the value it rejected, is replaced by its
+// MaskValue form. Validation errors returned by Reload are masked the same
+// way. Default: false.
+func OptMaskedLogging(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.maskedLogging = enabled
+	}
+}
+
+// Priority decides whether the file or the process environment wins when a
+// key is set in both; see OptEnvPriority.
+type Priority int
+
+const (
+	// PriorityFile makes values from the file win; the process environment
+	// is only a fallback. This is the default.
+	PriorityFile Priority = iota
+	// PriorityEnv makes non-empty process environment values win over the
+	// file, e.g. for local development with "export KEY=value".
+	PriorityEnv
+)
+
+// OptEnvPriority sets the lookup order between the file and the process
+// environment. The environment is only consulted while fallback is enabled
+// (OptFallbackToProcessEnv), so disabling it ignores the environment under
+// either priority.
+func OptEnvPriority(p Priority) Option {
+	return func(e *Env) {
+		e.opts.priority = p
+	}
+}
+
+// OptStrictParsing makes a file with malformed lines (no "=", an empty key,
+// a quoted value that is never closed) fail to load instead of skipping them.
+// The error lists every offending line by number (and key, where there is
+// one), so the initial load and reload logs point straight at the typo;
+// line contents are never quoted.
+// Default: false, malformed lines are skipped silently.
+func OptStrictParsing(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.strictParsing = enabled
+	}
+}
+
+// OptMetrics reports reloads, reload failures and watch errors to m as they
+// happen. The same counts are available from Stats.
+func OptMetrics(m Metrics) Option {
+	return func(e *Env) {
+		e.opts.metrics = m
+	}
+}
+
+// OptLocalOverride adds path, e.g. a gitignored ".env.local", as a developer
+// override loaded after every other file, so its keys win. It may be absent
+// at any time. It is watched like the other files, and a change to any of
+// them reloads the merged config.
+func OptLocalOverride(path string) Option {
+	return func(e *Env) {
+		e.opts.localOverride = path
+	}
+}
+
+// OptEscapeSequences decodes \n, \t, \r, \\ and \" in unquoted values as
+// well, for values copied from tools that escape newlines
+// (WELCOME_MSG=Hello\nWorld). Double-quoted values are always decoded and
+// single-quoted ones never are. Default: false, unquoted values are taken
+// as written.
+func OptEscapeSequences(enabled bool) Option {
+	return func(e *Env) {
+		e.opts.escapeSequences = enabled
+	}
+}
+
+// OptContext ties the watcher to ctx, typically the application's root
+// context from signal.NotifyContext: cancelling it stops the watcher as Stop
+// would, so a forgotten Stop can't leak the goroutine. Stop still works and
+// is a no-op once ctx is done. A nil ctx is ignored.
+func OptContext(ctx context.Context) Option {
+	return func(e *Env) {
+		if ctx != nil {
+			e.opts.ctx = ctx
+		}
+	}
+}
+
+// OptFormat sets how files are parsed, for names whose extension doesn't
+// tell. The default, FormatAuto, parses ".json" files as JSON, ".yaml" and
+// ".yml" files as YAML and everything else as .env; directory sources are
+// not affected. JSON and YAML top-level keys are read as written, nested
+// ones are flattened into environment-style keys: {"db": {"host": "x"}} is
+// read as DB_HOST=x. An array of scalars becomes one comma-separated value.
+func OptFormat(f Format) Option {
+	return func(e *Env) {
+		e.opts.format = f
+	}
+}
+
+// OptMissingHandler replaces what MustGetenv does with a missing key, by
+// default a panic naming the key and the file, e.g. to log.Fatalf instead.
+// If fn returns, MustGetenv returns "". A nil fn is ignored.
+func OptMissingHandler(fn func(key string)) Option {
+	return func(e *Env) {
+		if fn != nil {
+			e.opts.missingHandler = fn
+		}
+	}
+}
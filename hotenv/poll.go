@@ -0,0 +1,76 @@
+package hotenv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pollAndReload is the polling alternative to watchAndReload: every interval
+// it fingerprints the sources of each watched directory and reloads the
+// directories whose fingerprint changed. prev is the state the current config
+// was loaded from.
+func (e *Env) pollAndReload(ctx context.Context, interval time.Duration, prev map[string]string) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			cur := e.fingerprints()
+			for dir, fp := range cur {
+				if prev[dir] != fp {
+					e.reloadDir(dir)
+				}
+			}
+			prev = cur
+		}
+	}
+}
+
+// fingerprints describes every source by size, modification time and
+// permissions (see OptRequiredFileMode), grouped by watch directory. Paths
+// are stat'ed through symlinks, so a K8s ..data swap changes the fingerprint
+// even though the file name stays the same.
+func (e *Env) fingerprints() map[string]string {
+	_, srcs := e.resolveSources()
+	var b strings.Builder
+	out := make(map[string]string)
+	for _, src := range srcs {
+		dir := watchDir(src.path)
+		b.Reset()
+		b.WriteString(out[dir])
+		writeFingerprint(&b, src.path)
+		out[dir] = b.String()
+	}
+	return out
+}
+
+// writeFingerprint appends path's size, mtime and mode to b, or those of each
+// entry for a directory source. A missing path is recorded as such.
+func writeFingerprint(b *strings.Builder, path string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(b, "%s:missing;", path)
+		return
+	}
+	if !fi.IsDir() {
+		fmt.Fprintf(b, "%s:%d:%d:%o;", path, fi.Size(), fi.ModTime().UnixNano(), fi.Mode().Perm())
+		return
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		fmt.Fprintf(b, "%s:unreadable;", path)
+		return
+	}
+	for _, ent := range entries {
+		p := filepath.Join(path, ent.Name())
+		if fi, err := os.Stat(p); err == nil {
+			fmt.Fprintf(b, "%s:%d:%d:%o;", p, fi.Size(), fi.ModTime().UnixNano(), fi.Mode().Perm())
+		}
+	}
+}
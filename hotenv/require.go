@@ -0,0 +1,48 @@
+package hotenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MustGetenv returns the value of key, read like Getenv, for settings the
+// service can't run without. If the key is missing or empty, after the
+// process environment fallback, it panics with a message naming the key and
+// the loaded file; see WithMissingHandler to do something else.
+func MustGetenv(key string) string {
+	ensureStarted("")
+	return std().MustGetenv(key)
+}
+
+// Require checks that every key has a non-empty value and returns one error
+// listing all that don't, each wrapping ErrKeyNotFound. Call it once at
+// startup to report every missing setting at the same time.
+func Require(keys ...string) error {
+	ensureStarted("")
+	return std().Require(keys...)
+}
+
+// MustGetenv is the *Env counterpart of the package-level MustGetenv.
+func (e *Env) MustGetenv(key string) string {
+	v := e.get(key)
+	if v == "" {
+		if fn := e.opts.missingHandler; fn != nil {
+			fn(key)
+			return v
+		}
+		panic(fmt.Sprintf("hotenv: required key %s is not set in %s or the environment (check that the secret was injected)", key, strings.Join(e.paths, ", ")))
+	}
+	return v
+}
+
+// Require is the *Env counterpart of the package-level Require.
+func (e *Env) Require(keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if e.get(key) == "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrKeyNotFound, key))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package hotenv
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// sensitivePatterns are substrings that mark a key name as holding a secret.
+var sensitivePatterns = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "PRIVATE", "CREDENTIAL", "API_KEY", "APIKEY"}
+
+// redacted replaces the value of a sensitive key wherever values would
+// otherwise be shown.
+const redacted = "***"
+
+// Redact returns a copy of m with the values of sensitive keys replaced by
+// "***": keys registered with WithSensitiveKeys and keys whose names look
+// like secrets (PASSWORD, SECRET, TOKEN, ...). Use it before handing a config
+// map, e.g. from GetAll or an OnReload hook, to a logger.
+func Redact(m map[string]string) map[string]string {
+	return std().Redact(m)
+}
+
+// Redact is the *Env counterpart of the package-level Redact.
+func (e *Env) Redact(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = e.mask(k, v)
+	}
+	return out
+}
+
+// MaskValue returns value with all but its last few characters replaced by
+// "*" (the last 4 of values of 12 characters or more, the last 2 of values
+// of 6 or more, none of shorter ones), or "***" if key is sensitive. It is
+// what hotenv prints wherever a diagnostic would otherwise show a value.
+func MaskValue(key, value string) string {
+	return std().MaskValue(key, value)
+}
+
+// MaskValue is the *Env counterpart of the package-level MaskValue.
+func (e *Env) MaskValue(key, value string) string {
+	if e.isSensitive(key) {
+		return redacted
+	}
+	r := []rune(value)
+	keep := 0
+	switch {
+	case len(r) >= 12:
+		keep = 4
+	case len(r) >= 6:
+		keep = 2
+	}
+	return strings.Repeat("*", len(r)-keep) + string(r[len(r)-keep:])
+}
+
+// --------- Internals ----------
+
+// minScrubLen is the shortest value scrub replaces; shorter values such as
+// "1" or "on" would only mangle unrelated text.
+const minScrubLen = 4
+
+// scrub replaces every value of c found in s by its MaskValue form, longest
+// values first so one value can't partially unmask another.
+func (e *Env) scrub(s string, c config) string {
+	keys := make([]string, 0, len(c.m))
+	for k, v := range c.m {
+		if len(v) >= minScrubLen && strings.Contains(s, v) {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int { return cmp.Compare(len(c.m[b]), len(c.m[a])) })
+	for _, k := range keys {
+		s = strings.ReplaceAll(s, c.m[k], e.MaskValue(k, c.m[k]))
+	}
+	return s
+}
+
+// isSensitive reports whether key was registered as sensitive or looks like
+// it holds a secret.
+func (e *Env) isSensitive(key string) bool {
+	if e.opts.sensitiveKeys[key] || isSecretName(key) {
+		return true
+	}
+	if e.opts.caseInsensitive {
+		// keys are registered as written but stored upper-cased
+		for k := range e.opts.sensitiveKeys {
+			if e.foldKey(k) == e.foldKey(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSecretName reports whether key's name looks like it holds a secret.
+func isSecretName(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, p := range sensitivePatterns {
+		if strings.Contains(upper, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns value, or "***" if key is sensitive.
+func (e *Env) mask(key, value string) string {
+	if e.isSensitive(key) {
+		return redacted
+	}
+	return value
+}
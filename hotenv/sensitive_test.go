@@ -0,0 +1,17 @@
+package hotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSensitiveKeysCaseInsensitive(t *testing.T) {
+	e := startEnv(t, writeFile(t, ".env", "db_pass=hunter22\n"), OptCaseInsensitiveKeys(true), OptSensitiveKeys("db_pass"))
+	b, err := e.DumpJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "hunter22") {
+		t.Errorf("DumpJSON = %s, want db_pass masked", b)
+	}
+}
@@ -0,0 +1,132 @@
+package hotenv
+
+import (
+	"expvar"
+	"log/slog"
+	"time"
+)
+
+// EnvStats describes the config currently held by an Env and its reload history.
+type EnvStats struct {
+	// Keys is the number of keys loaded from the file.
+	Keys int
+	// ConfigBytes approximates the memory held by the config as the sum of
+	// key and value lengths.
+	ConfigBytes int
+	// Reloads counts successful reloads since start that changed the config,
+	// not including the initial load.
+	Reloads int64
+	// ReloadFailures counts reloads that were attempted and failed.
+	ReloadFailures int64
+	// WatchErrors counts errors reported by the file watcher.
+	WatchErrors int64
+	// LastReload is when the current config was loaded; zero if nothing was ever loaded.
+	LastReload time.Time
+	// LastError is the error of the most recent load, the initial one
+	// included, or "" if it succeeded.
+	LastError string
+	// LastErrorAt is when the most recent failed reload happened; zero if
+	// no reload ever failed. It is kept after a later reload succeeds.
+	LastErrorAt time.Time
+	// ReloadError is the error of the failed reload at LastErrorAt, nil if
+	// no reload ever failed. Unlike LastError it never reflects the initial
+	// load, which New returns and Init logs, and it can be inspected with
+	// errors.Is, e.g. for fs.ErrNotExist.
+	ReloadError error
+}
+
+// Metrics receives reload events as they happen, for bridging to a metrics
+// system such as Prometheus without scraping logs. Methods are called on the
+// reload and watcher goroutines and must not block.
+type Metrics interface {
+	// IncReload is called after each successful reload.
+	IncReload()
+	// IncReloadFailure is called after each failed reload.
+	IncReloadFailure()
+	// IncWatchError is called for each error reported by the file watcher.
+	IncWatchError()
+}
+
+// Stats returns statistics for the default config. The fields are plain
+// values, so a Prometheus collector can call it from Collect and turn
+// Reloads and ReloadFailures into counters and Keys into a gauge.
+func Stats() EnvStats {
+	ensureStarted("")
+	return std().Stats()
+}
+
+// Stats returns statistics for the config held by e.
+func (e *Env) Stats() EnvStats {
+	c := e.snapshot()
+	s := EnvStats{
+		Keys:           len(c.m),
+		ConfigBytes:    c.size(),
+		Reloads:        e.reloads.Load(),
+		ReloadFailures: e.reloadFailures.Load(),
+		WatchErrors:    e.watchErrors.Load(),
+		LastReload:     e.LastReload(),
+	}
+	s.LastError, _ = e.lastError.Load().(string)
+	if ns := e.lastFailure.Load(); ns != 0 {
+		s.LastErrorAt = time.Unix(0, ns)
+	}
+	if err := e.reloadErr.Load(); err != nil {
+		s.ReloadError = *err
+	}
+	return s
+}
+
+// Generation returns a counter that starts at 1 with the initial load and
+// grows by one with every reload that changed the config; 0 means nothing was ever
+// loaded. Poll it to rebuild derived state only when the config changed.
+func Generation() uint64 {
+	ensureStarted("")
+	return std().Generation()
+}
+
+// Generation is the *Env counterpart of the package-level Generation.
+func (e *Env) Generation() uint64 {
+	return e.generation.Load()
+}
+
+// LastReload returns when the current default config was loaded, or the
+// zero time if nothing was ever loaded.
+func LastReload() time.Time {
+	ensureStarted("")
+	return std().LastReload()
+}
+
+// LastReload is the *Env counterpart of the package-level LastReload.
+func (e *Env) LastReload() time.Time {
+	if ns := e.lastReload.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// publishExpvar registers e's statistics as an expvar.Func under name.
+func (e *Env) publishExpvar(name string) {
+	if expvar.Get(name) != nil {
+		e.logWarn("expvar already published, skipping", slog.String("name", name))
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		s := e.Stats()
+		out := map[string]any{
+			"keys":            s.Keys,
+			"reloads":         s.Reloads,
+			"reload_failures": s.ReloadFailures,
+			"watch_errors":    s.WatchErrors,
+			"last_reload":     nil,
+			"last_error":      s.LastError,
+			"last_error_at":   nil,
+		}
+		if !s.LastReload.IsZero() {
+			out["last_reload"] = s.LastReload.Format(time.RFC3339)
+		}
+		if !s.LastErrorAt.IsZero() {
+			out["last_error_at"] = s.LastErrorAt.Format(time.RFC3339)
+		}
+		return out
+	}))
+}
@@ -0,0 +1,35 @@
+package hotenv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsReloadError(t *testing.T) {
+	path := writeFile(t, "secrets.json", `{"A": `)
+	e := newEnv(OptPollingInterval(time.Hour))
+	e.Init(path)
+	defer e.Stop()
+
+	s := e.Stats()
+	if s.LastError == "" {
+		t.Error("LastError is empty after a failed initial load")
+	}
+	if s.ReloadError != nil || s.ReloadFailures != 0 {
+		t.Errorf("a failed initial load counted as a failed reload: %v, %d", s.ReloadError, s.ReloadFailures)
+	}
+
+	rewrite(t, path, `{"A": "1"}`)
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	rewrite(t, path, `{"A": `)
+	err := e.Reload()
+	if err == nil {
+		t.Fatal("want an error for a corrupt file")
+	}
+	s = e.Stats()
+	if s.ReloadError == nil || s.ReloadError.Error() != err.Error() || s.ReloadFailures != 1 || s.LastErrorAt.IsZero() {
+		t.Errorf("after a failed reload: ReloadError %v, ReloadFailures %d, LastErrorAt %v", s.ReloadError, s.ReloadFailures, s.LastErrorAt)
+	}
+}
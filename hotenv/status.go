@@ -0,0 +1,82 @@
+package hotenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StatusHandler returns an http.Handler that reports the health of the
+// default config as JSON: the watched path, the time of the last successful
+// load, the key count, reload counters and the last error. It responds 200
+// while the most recent load succeeded and 503 otherwise. No values are
+// included.
+func StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ensureStarted("")
+		std().StatusHandler().ServeHTTP(w, r)
+	})
+}
+
+// IsReady reports whether the default config has been loaded successfully
+// at least once, e.g. for a readiness probe that should fail until the
+// secrets file has been written. A later failed reload keeps the last good
+// config, so IsReady stays true.
+func IsReady() bool {
+	ensureStarted("")
+	return std().IsReady()
+}
+
+// IsReady is the *Env counterpart of the package-level IsReady.
+func (e *Env) IsReady() bool {
+	return e.generation.Load() > 0
+}
+
+// DumpJSON returns the default config as a JSON object with keys in sorted
+// order, for diffing the effective config of two replicas. Values of
+// sensitive keys (see Redact) appear as "***". Keys only present in the
+// process environment are not included.
+func DumpJSON() ([]byte, error) {
+	ensureStarted("")
+	return std().DumpJSON()
+}
+
+// DumpJSON is the *Env counterpart of the package-level DumpJSON.
+func (e *Env) DumpJSON() ([]byte, error) {
+	// encoding/json sorts map keys
+	return json.Marshal(e.Redact(e.snapshot().m))
+}
+
+// StatusHandler is the *Env counterpart of the package-level StatusHandler.
+func (e *Env) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s := e.Stats()
+		body := struct {
+			Path           string  `json:"path"`
+			LastReload     *string `json:"last_reload"`
+			Keys           int     `json:"keys"`
+			LastError      string  `json:"last_error,omitempty"`
+			Reloads        int64   `json:"reloads"`
+			ReloadFailures int64   `json:"reload_failures"`
+			WatchErrors    int64   `json:"watch_errors"`
+		}{
+			Path:           e.Path(),
+			Keys:           s.Keys,
+			LastError:      s.LastError,
+			Reloads:        s.Reloads,
+			ReloadFailures: s.ReloadFailures,
+			WatchErrors:    s.WatchErrors,
+		}
+		if !s.LastReload.IsZero() {
+			t := s.LastReload.Format(time.RFC3339)
+			body.LastReload = &t
+		}
+		status := http.StatusOK
+		if s.LastError != "" || len(e.paths) == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
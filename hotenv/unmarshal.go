@@ -0,0 +1,132 @@
+package hotenv
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal fills the exported fields of the struct v points to from the
+// default config. Each field reads the key named by its `env:"KEY"` tag, or
+// its name in upper case; `env:"-"` skips the field. A missing or empty key
+// uses the `default:"..."` tag if there is one, fails if the field is tagged
+// `required:"true"`, and leaves the field untouched otherwise.
+//
+// Supported field types are string, int, int64, uint, bool, float64,
+// time.Duration and []string (comma-separated), parsed like the typed
+// getters. Every missing or unparsable field is reported in one error;
+// values never appear in it.
+//
+//	var cfg struct {
+//		DBURL   string        `env:"DB_URL" required:"true"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+//		Debug   bool
+//	}
+//	if err := hotenv.Unmarshal(&cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func Unmarshal(v any) error {
+	ensureStarted("")
+	return std().Unmarshal(v)
+}
+
+// Unmarshal is the *Env counterpart of the package-level Unmarshal.
+func (e *Env) Unmarshal(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("hotenv: Unmarshal needs a non-nil struct pointer, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var errs []error
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := cmp.Or(f.Tag.Get("env"), strings.ToUpper(f.Name))
+		if key == "-" {
+			continue
+		}
+		val := e.getFrom(e.snapshot(), key)
+		if val == "" {
+			// a default tag wins over a registered default, like a def argument
+			def, ok := f.Tag.Lookup("default")
+			if !ok {
+				def, ok = e.registeredDefault(key)
+			}
+			switch {
+			case ok:
+				val = def
+			case f.Tag.Get("required") == "true":
+				errs = append(errs, fmt.Errorf("%s (%s): %w", f.Name, key, ErrKeyNotFound))
+				continue
+			default:
+				continue
+			}
+		}
+		if err := setField(rv.Field(i), val); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", f.Name, key, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("hotenv: unmarshal: %w", err)
+	}
+	return nil
+}
+
+// --------- Internals ----------
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// setField parses s into fv according to its type. Errors name the expected
+// type only, never the value.
+func setField(fv reflect.Value, s string) error {
+	// strings keep the value as loaded, padding included; everything else
+	// is parsed with surrounding whitespace ignored
+	raw := s
+	s = strings.TrimSpace(s)
+	var err error
+	switch {
+	case fv.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			fv.SetInt(int64(d))
+		}
+	case fv.Kind() == reflect.String:
+		fv.SetString(raw)
+	case fv.Kind() == reflect.Int || fv.Kind() == reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, fv.Type().Bits()); err == nil {
+			fv.SetInt(n)
+		}
+	case fv.Kind() == reflect.Uint:
+		var n uint
+		if n, err = parseUint(s); err == nil {
+			fv.SetUint(uint64(n))
+		}
+	case fv.Kind() == reflect.Bool:
+		var b bool
+		if b, err = parseBool(s); err == nil {
+			fv.SetBool(b)
+		}
+	case fv.Kind() == reflect.Float64:
+		var f float64
+		if f, err = parseFloat(s); err == nil {
+			fv.SetFloat(f)
+		}
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		fv.Set(reflect.ValueOf(splitList(s, ",")).Convert(fv.Type()))
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value", fv.Type())
+	}
+	return nil
+}
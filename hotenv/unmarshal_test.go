@@ -0,0 +1,25 @@
+package hotenv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnmarshalWhitespace(t *testing.T) {
+	e := startEnv(t, writeFile(t, ".env", "GREETING=\"  hi  \"\nPORT=\" 8080 \"\nDEBUG=\" true\"\nTIMEOUT=\"5s \"\n"))
+	var cfg struct {
+		Greeting string
+		Port     int
+		Debug    bool
+		Timeout  time.Duration
+	}
+	if err := e.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Greeting != "  hi  " {
+		t.Errorf("Greeting = %q, want the padding kept", cfg.Greeting)
+	}
+	if cfg.Port != 8080 || !cfg.Debug || cfg.Timeout != 5*time.Second {
+		t.Errorf("padded values parsed as Port %d, Debug %v, Timeout %v", cfg.Port, cfg.Debug, cfg.Timeout)
+	}
+}
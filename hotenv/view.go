@@ -0,0 +1,79 @@
+package hotenv
+
+import "time"
+
+// View is a read-only snapshot of a candidate config, handed to typed
+// validators before the config is stored. Its getters behave exactly like
+// the ones on Env, including the process-env fallback.
+type View struct {
+	e *Env
+}
+
+func (e *Env) view(c config) *View {
+	v := &Env{opts: e.opts}
+	v.opts.warnOnDefault = false
+	v.fallbackToProcessEnv.Store(e.fallbackToProcessEnv.Load())
+	e.defaults.Range(func(k, d any) bool {
+		v.defaults.Store(k, d)
+		return true
+	})
+	v.cfg.Store(c)
+	return &View{e: v}
+}
+
+// Getenv returns the value for key. If not present, it returns def (if provided) or "".
+func (v *View) Getenv(key string, def ...string) string { return v.e.Getenv(key, def...) }
+
+// Lookup returns the value for key and whether it is set.
+func (v *View) Lookup(key string) (string, bool) { return v.e.Lookup(key) }
+
+// GetInt returns key parsed as an int, or def.
+func (v *View) GetInt(key string, def ...int) int { return v.e.GetInt(key, def...) }
+
+// GetInt64 returns key parsed as an int64, or def.
+func (v *View) GetInt64(key string, def ...int64) int64 { return v.e.GetInt64(key, def...) }
+
+// GetUint returns key parsed as a uint, or def.
+func (v *View) GetUint(key string, def ...uint) uint { return v.e.GetUint(key, def...) }
+
+// GetBool returns key parsed as a bool, or def.
+func (v *View) GetBool(key string, def ...bool) bool { return v.e.GetBool(key, def...) }
+
+// FlagState reports a boolean flag and whether it was set explicitly.
+func (v *View) FlagState(key string) (enabled, explicit bool) { return v.e.FlagState(key) }
+
+// GetFloat returns key parsed as a float64, or def.
+func (v *View) GetFloat(key string, def ...float64) float64 { return v.e.GetFloat(key, def...) }
+
+// GetFloat64 is an alias for GetFloat.
+func (v *View) GetFloat64(key string, def ...float64) float64 { return v.e.GetFloat(key, def...) }
+
+// GetSlice returns the value of key split on sep, or def.
+func (v *View) GetSlice(key string, sep string, def ...[]string) []string {
+	return v.e.GetSlice(key, sep, def...)
+}
+
+// GetStringSlice is an alias for GetSlice.
+func (v *View) GetStringSlice(key string, sep string, def ...[]string) []string {
+	return v.e.GetSlice(key, sep, def...)
+}
+
+// GetBase64 returns key decoded as base64, or ErrKeyNotFound.
+func (v *View) GetBase64(key string) ([]byte, error) { return v.e.GetBase64(key) }
+
+// GetIntE returns key parsed as an int, or an error; see GetIntE.
+func (v *View) GetIntE(key string) (int, error) { return v.e.GetIntE(key) }
+
+// GetBoolE returns key parsed as a bool, or an error; see GetBoolE.
+func (v *View) GetBoolE(key string) (bool, error) { return v.e.GetBoolE(key) }
+
+// GetDurationE returns key parsed as a time.Duration, or an error.
+func (v *View) GetDurationE(key string) (time.Duration, error) { return v.e.GetDurationE(key) }
+
+// Unmarshal fills the struct v points to from the candidate config.
+func (v *View) Unmarshal(dst any) error { return v.e.Unmarshal(dst) }
+
+// GetDuration returns key parsed as a time.Duration, or def.
+func (v *View) GetDuration(key string, def ...time.Duration) time.Duration {
+	return v.e.GetDuration(key, def...)
+}
@@ -0,0 +1,137 @@
+package hotenv
+
+import (
+	"context"
+	"sync"
+)
+
+// Watch returns a channel that receives the new value of key every time a
+// reload actually changes it ("" once the key is removed). The channel holds
+// one pending value; if the reader falls behind, older values are dropped in
+// favour of the latest so the reload path never blocks.
+func Watch(key string) <-chan string {
+	return std().Watch(key)
+}
+
+// Unwatch stops delivery to ch, a channel returned by Watch(key), and closes it.
+func Unwatch(key string, ch <-chan string) {
+	std().Unwatch(key, ch)
+}
+
+// Subscribe is Watch with its Unwatch bundled: it returns the channel and a
+// func that stops delivery and closes the channel, so a consumer can range
+// over the channel until the func is called. Calling the func more than once
+// is safe.
+//
+//	ch, unsubscribe := hotenv.Subscribe("LOG_LEVEL")
+//	defer unsubscribe()
+//	go func() {
+//		for lvl := range ch {
+//			setLevel(lvl)
+//		}
+//	}()
+func Subscribe(key string) (<-chan string, func()) {
+	return std().Subscribe(key)
+}
+
+// WaitForKey blocks until key has a non-empty value (from the file, or from
+// the process environment if fallback is enabled) and returns it. It returns
+// immediately if the key is already set, and ctx.Err() if ctx is done first.
+// It wakes up on reloads rather than polling, so a key that only appears in
+// the process environment later is not noticed until the next reload.
+func WaitForKey(ctx context.Context, key string) (string, error) {
+	ensureStarted("")
+	return std().WaitForKey(ctx, key)
+}
+
+// WaitForKey is the *Env counterpart of the package-level WaitForKey.
+func (e *Env) WaitForKey(ctx context.Context, key string) (string, error) {
+	// register before the first check so a reload in between isn't missed
+	reloaded := make(chan struct{}, 1)
+	remove := e.addListener(func(_, _ config) {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	})
+	defer remove()
+
+	for {
+		// a registered default doesn't count, the key itself must appear
+		if v := e.getFrom(e.snapshot(), key); v != "" {
+			return v, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-reloaded:
+		}
+	}
+}
+
+// Watch is the *Env counterpart of the package-level Watch.
+func (e *Env) Watch(key string) <-chan string {
+	ch := make(chan string, 1)
+	key = e.foldKey(key)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watchers == nil {
+		e.watchers = make(map[string][]chan string)
+	}
+	e.watchers[key] = append(e.watchers[key], ch)
+	return ch
+}
+
+// Subscribe is the *Env counterpart of the package-level Subscribe.
+func (e *Env) Subscribe(key string) (<-chan string, func()) {
+	ch := e.Watch(key)
+	return ch, sync.OnceFunc(func() { e.Unwatch(key, ch) })
+}
+
+// Unwatch is the *Env counterpart of the package-level Unwatch.
+func (e *Env) Unwatch(key string, ch <-chan string) {
+	key = e.foldKey(key)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	chans := e.watchers[key]
+	for i, c := range chans {
+		if c == ch {
+			close(c)
+			e.watchers[key] = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(e.watchers[key]) == 0 {
+		delete(e.watchers, key)
+	}
+}
+
+// notifyWatchers sends the new value of every watched key that differs
+// between old and cur. Sends never block: a full channel has its stale value
+// replaced. Only the reload path sends, under reloadMu, so the drain below
+// can't race with another sender.
+func (e *Env) notifyWatchers(old, cur config) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, chans := range e.watchers {
+		ov, oldOK := old.m[key]
+		nv, newOK := cur.m[key]
+		if ov == nv && oldOK == newOK {
+			continue
+		}
+		for _, ch := range chans {
+			select {
+			case ch <- nv:
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+				select {
+				case ch <- nv:
+				default:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package hotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchCaseInsensitive(t *testing.T) {
+	path := writeFile(t, ".env", "LOG_LEVEL=info\n")
+	e := startEnv(t, path, OptCaseInsensitiveKeys(true))
+	ch, unsubscribe := e.Subscribe("log_level")
+
+	rewrite(t, path, "log_level=debug\n")
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-ch:
+		if v != "debug" {
+			t.Errorf("Watch delivered %q, want %q", v, "debug")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no value delivered for a key watched in another case")
+	}
+
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+}
+
+func TestAtomicSymlinkModeRename(t *testing.T) {
+	path := writeFile(t, ".env", "TOKEN=old\n")
+	// the debounce never fires during the test, only the swap path reloads
+	e, err := New(path, OptAtomicSymlinkMode(true), OptDebounce(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+	ch := e.Watch("TOKEN")
+
+	// swap the file the way a Kubernetes volume update does
+	tmp := filepath.Join(filepath.Dir(path), "..swap")
+	if err := os.WriteFile(tmp, []byte("TOKEN=new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-ch:
+		if v != "new" {
+			t.Errorf("reload after rename delivered %q, want %q", v, "new")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after the file was replaced by a rename")
+	}
+}
+
+func TestAtomicSymlinkModeDataSwap(t *testing.T) {
+	// the layout of a Kubernetes Secret volume: .env -> ..data/.env,
+	// ..data -> a timestamped directory holding the content
+	dir := t.TempDir()
+	version := func(name, content string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, ".env"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(name, filepath.Join(dir, "..data_tmp")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	version("..v1", "TOKEN=old\n")
+	path := filepath.Join(dir, ".env")
+	if err := os.Symlink(filepath.Join("..data", ".env"), path); err != nil {
+		t.Fatal(err)
+	}
+	e, err := New(path, OptAtomicSymlinkMode(true), OptDebounce(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+	ch := e.Watch("TOKEN")
+
+	version("..v2", "TOKEN=new\n")
+	select {
+	case v := <-ch:
+		if v != "new" {
+			t.Errorf("reload after the ..data swap delivered %q, want %q", v, "new")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after ..data was swapped")
+	}
+}
+
+func TestWatcherSkipsDeletedLayer(t *testing.T) {
+	base := writeFile(t, "base.env", "A=1\n")
+	override := writeFile(t, "override.env", "A=2\nB=3\n")
+	missing := 0
+	e, err := NewMulti([]string{base, override}, OptDebounce(10*time.Millisecond), OptOnSourceMissing(func() { missing++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+	ch := e.Watch("B")
+
+	if err := os.Remove(override); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-ch:
+		if v != "" {
+			t.Errorf("B = %q after its file was deleted, want it dropped", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deleting a layer didn't reload")
+	}
+	if got := e.Getenv("A"); got != "1" {
+		t.Errorf("A = %q, want the value from the remaining file", got)
+	}
+	if missing != 0 {
+		t.Errorf("OnSourceMissing called %d times for a skipped layer", missing)
+	}
+}
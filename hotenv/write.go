@@ -0,0 +1,172 @@
+package hotenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --------- Public API ----------
+
+// Setenv writes key=value into the default config's file, replacing the
+// key's existing entry or appending one, e.g. for a credential the service
+// rotated itself. The file is rewritten atomically (temporary file plus
+// rename) and created if it doesn't exist; the watcher then reloads it, so
+// the new value is visible once the reload completes (see WaitForKey).
+// With several files the last one, which takes precedence, is written.
+// A directory source gets one file named after the key. JSON and YAML files
+// and read-only mounts such as a Kubernetes Secret can't be written, nor
+// can encrypted files (see WithDecryptor).
+func Setenv(key, value string) error {
+	ensureStarted("")
+	return std().Setenv(key, value)
+}
+
+// Setenv is the *Env counterpart of the package-level Setenv. Calls are
+// serialized per Env; writers in other processes are not coordinated.
+func (e *Env) Setenv(key, value string) error {
+	if len(e.paths) == 0 {
+		return errors.New("hotenv: Setenv called before Init")
+	}
+	path := e.paths[len(e.paths)-1]
+	// map the key as read back to the key as written in the file
+	name := e.opts.keyPrefix + key
+	if ns := e.prefixes[path]; ns != "" {
+		var ok bool
+		if name, ok = strings.CutPrefix(name, ns+"_"); !ok {
+			return fmt.Errorf("hotenv: Setenv %s: key is outside the namespace %s of %s", key, ns, path)
+		}
+	}
+	if name == "" || strings.ContainsAny(name, "= \t\r\n#") {
+		return fmt.Errorf("hotenv: Setenv: invalid key %q", key)
+	}
+
+	if e.opts.decryptor != nil {
+		// writing plaintext next to, or into, encrypted files would defeat them
+		return fmt.Errorf("hotenv: Setenv %s: not supported with a decryptor", key)
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return writeFileAtomic(filepath.Join(path, name), []byte(value))
+	}
+	if f := e.formatFor(path); f == FormatJSON || f == FormatYAML {
+		return fmt.Errorf("hotenv: Setenv %s: writing JSON or YAML files is not supported", key)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("hotenv: Setenv %s: %w", key, err)
+	}
+	if err := writeFileAtomic(path, e.setLine(b, name, value)); err != nil {
+		return fmt.Errorf("hotenv: Setenv %s: %w", key, err)
+	}
+	return nil
+}
+
+// --------- Internals ----------
+
+// setLine returns the .env content b with every entry for key replaced by
+// key=value, or with key=value appended if there is none. Comments, blank
+// lines, other entries and an "export " prefix are kept as written.
+func (e *Env) setLine(b []byte, key, value string) []byte {
+	entry := key + "=" + quoteValue(value)
+	lines := strings.Split(string(b), "\n")
+	if n := len(lines); lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	var out []string
+	found := false
+	for i := 0; i < len(lines); i++ {
+		trim := strings.TrimSpace(lines[i])
+		if trim == "" || strings.HasPrefix(trim, "#") {
+			out = append(out, lines[i])
+			continue
+		}
+		rest := stripExport(trim)
+		k, body, ok := strings.Cut(rest, "=")
+		if !ok {
+			out = append(out, lines[i])
+			continue
+		}
+		// find the last line of the entry, skipping a multi-line or continued value
+		end := i
+		if body = strings.TrimLeft(body, " \t"); body != "" && (body[0] == '"' || body[0] == '\'') {
+			q := body[0]
+			if _, clean := closingQuote(body[1:], q); !clean {
+				for end+1 < len(lines) && !closesQuote(strings.TrimRight(lines[end+1], " \t\r"), q) {
+					end++
+				}
+				end = min(end+1, len(lines)-1)
+			}
+		} else if _, cont := cutContinuation(stripInlineComment(body)); cont {
+			// an unquoted value continued by trailing backslashes
+			for end+1 < len(lines) {
+				end++
+				if _, cont = cutContinuation(stripInlineComment(strings.TrimRight(lines[end], " \t\r"))); !cont {
+					break
+				}
+			}
+		}
+		if e.foldKey(strings.TrimSpace(k)) != e.foldKey(key) {
+			out = append(out, lines[i:end+1]...)
+		} else {
+			prefix := ""
+			if rest != trim {
+				prefix = "export "
+			}
+			out = append(out, prefix+entry)
+			found = true
+		}
+		i = end
+	}
+	if !found {
+		out = append(out, entry)
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// quoteValue renders v so that loadEnvFile reads it back unchanged: as is
+// when that is safe, otherwise double-quoted with \n, \r, \\ and \" escaped.
+func quoteValue(v string) string {
+	if v == strings.TrimSpace(v) && !strings.ContainsAny(v, "\n\r\"'#\\") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(v) + `"`
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, keeping the mode of an existing file (0600 for a new one).
+// The temporary name is a dotfile, so a directory source never loads it.
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o600)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op once renamed
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
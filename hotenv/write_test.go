@@ -0,0 +1,31 @@
+package hotenv
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetenvThenWaitForKey(t *testing.T) {
+	e, err := New(writeFile(t, ".env", "# managed by the service\nA=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+
+	if err := e.Setenv("TOKEN", "line1\nline2"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := e.WaitForKey(ctx, "TOKEN")
+	if err != nil {
+		t.Fatalf("WaitForKey: %v", err)
+	}
+	if got != "line1\nline2" {
+		t.Errorf("WaitForKey = %q, want %q", got, "line1\nline2")
+	}
+	if got := e.Getenv("A"); got != "1" {
+		t.Errorf("A = %q after Setenv, want it kept", got)
+	}
+}